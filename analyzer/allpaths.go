@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkAllPaths reports every return of caller that can be reached from
+// the entry of caller without passing a call that reaches the callee.
+//
+// Deferred calls count for all returns after the defer statement.
+// Goroutines are not waited for, so go statements never count.
+// Returns after a recovered panic are ignored.
+func (r *rule) checkAllPaths(s *searcher, rep *reporter, caller *ssa.Function) {
+	if len(caller.Blocks) == 0 {
+		return
+	}
+	called := calledBlocks(caller, s.reachingCalls(s.node(caller), r, true))
+
+	for _, b := range caller.Blocks {
+		if called[b.Index] || len(b.Instrs) == 0 {
+			continue
+		}
+		ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return)
+		if !ok {
+			continue
+		}
+		rep.reportFinding(r, caller, analysis.Diagnostic{
+			Pos:     returnPos(caller, ret),
+			Message: r.message(r.msg, MessageData{Caller: caller.Name(), Callee: r.Callee.Name}),
+		})
+	}
+}
+
+// calledBlocks returns for every block of fn, if a reaching call is made
+// on all paths from the entry to the end of the block.
+// The recover block is treated as called.
+func calledBlocks(fn *ssa.Function, reaching map[ssa.CallInstruction]bool) []bool {
+	called := make([]bool, len(fn.Blocks))
+	for i := range called {
+		called[i] = true
+	}
+
+	// Start with all blocks called and remove blocks with an uncalled predecessor
+	// until nothing changes.
+	for changed := true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			if !called[b.Index] || callsAny(b.Instrs, reaching) || calledBefore(fn, b, called) {
+				continue
+			}
+			called[b.Index] = false
+			changed = true
+		}
+	}
+	return called
+}
+
+// calledBefore returns true if a reaching call is made on all paths
+// from the entry to the start of b, see [calledBlocks].
+func calledBefore(fn *ssa.Function, b *ssa.BasicBlock, called []bool) bool {
+	if b.Index == 0 {
+		return false
+	}
+	if b == fn.Recover {
+		return true
+	}
+	for _, p := range b.Preds {
+		if !called[p.Index] {
+			return false
+		}
+	}
+	return true
+}
+
+// reachingCalls returns the call instructions of node whose callees all reach the callee of r.
+// Deferred calls are included if defers is true.
+func (s *searcher) reachingCalls(node *callgraph.Node, r *rule, defers bool) map[ssa.CallInstruction]bool {
+	reaching := make(map[ssa.CallInstruction]bool)
+	for _, e := range node.Out {
+		switch e.Site.(type) {
+		case *ssa.Call:
+		case *ssa.Defer:
+			if !defers {
+				continue
+			}
+		default:
+			continue
+		}
+		ok, seen := reaching[e.Site]
+		if seen && !ok {
+			continue
+		}
+		reaching[e.Site] = s.search(e.Callee, r) != nil
+	}
+	return reaching
+}
+
+// callsAny returns true if instrs contain a reaching call.
+func callsAny(instrs []ssa.Instruction, reaching map[ssa.CallInstruction]bool) bool {
+	for _, instr := range instrs {
+		if call, ok := instr.(ssa.CallInstruction); ok && reaching[call] {
+			return true
+		}
+	}
+	return false
+}
+
+// returnPos returns the position of ret.
+// Implicit returns at the end of the function are at its closing brace.
+func returnPos(fn *ssa.Function, ret *ssa.Return) token.Pos {
+	if ret.Pos().IsValid() {
+		return ret.Pos()
+	}
+	switch syntax := fn.Syntax().(type) {
+	case *ast.FuncDecl:
+		if syntax.Body != nil {
+			return syntax.Body.Rbrace
+		}
+	case *ast.FuncLit:
+		return syntax.Body.Rbrace
+	}
+	return fn.Pos()
+}
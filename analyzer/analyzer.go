@@ -1,17 +1,22 @@
 package analyzer
 
 import (
+	"cmp"
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
+	"path"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
 	"golang.org/x/tools/go/callgraph"
-	"golang.org/x/tools/go/callgraph/vta"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
 
@@ -19,14 +24,31 @@ import (
 )
 
 func init() {
+	Analyzer.Flags.StringVar(&opts.ConfigFile, "config", "", "JSON config file with a list of rules")
 	Analyzer.Flags.Func("skip.file", "skip all files with specified suffixes", setSlice(&opts.SkipFileSuffixes))
-
-	Analyzer.Flags.StringVar(&calleeOpts.Name, "callee.name", "", "callee function name")
+	Analyzer.Flags.Func("mode", "rule mode: require (callers must call the callee), forbid (callers must not call the callee), allpaths (callers must call the callee before every return), order (callers must call the callee before -guarded.name) or pair (calls of the callee must be followed by one of -release.names)", setMode(&opts.Mode))
+	Analyzer.Flags.StringVar(&opts.Guarded, "guarded.name", "", "guarded function name, which must not be called before the callee in mode order (same forms as -callee.name)")
+	Analyzer.Flags.Func("release.names", "release function names, one of which must be called after the callee in mode pair (comma separated, same forms as -callee.name)", setSlice(&opts.Release))
+	Analyzer.Flags.BoolVar(&opts.ReportPaths, "report.paths", false, "report the call path of callers that call the callee function")
+	Analyzer.Flags.Func("callgraph", "call graph algorithm: static, cha, rta, vta (default) or vta+cha", setCallGraph(&opts.CallGraph))
+	Analyzer.Flags.Func("reflection", "calls through reflection, which can't be resolved: ignore (default) or conservative (report callers that may reach the callee)", setReflection(&opts.Reflection))
+	Analyzer.Flags.Func("require", "how the callee function has to be called: any (default), defer or direct", setRequire(&opts.Require))
+	Analyzer.Flags.BoolVar(&opts.IgnoreGo, "ignore.go", false, "calls in go statements don't reach the callee function")
+	Analyzer.Flags.BoolVar(&opts.IgnoreChannels, "ignore.channels", false, "functions sent over channels don't reach the callee function")
+	Analyzer.Flags.StringVar(&opts.BaselineFile, "baseline", "", "JSON baseline file with known findings, which are not reported")
+	Analyzer.Flags.BoolVar(&opts.UpdateBaseline, "baseline.update", false, "write all findings to the baseline file instead of reporting them")
+	Analyzer.Flags.Func("baseline.packages", "packages whose findings are written by -baseline.update (comma separated import paths, default: the requested packages)", setSlice(&opts.BaselinePackages))
+
+	Analyzer.Flags.StringVar(&calleeOpts.Name, "callee.name", "", "callee function name (Func, pkg/path.Func, pkg/path.Type.Method or (*pkg/path.Type).Method)")
 	Analyzer.Flags.Func("callee.params", "callee function params (comma separated, in order)", setSlice(&calleeOpts.Params))
 	Analyzer.Flags.Func("callee.results", "callee function results (comma separated, in order)", setSlice(&calleeOpts.Results))
 	Analyzer.Flags.Func("callee.pkg", "callee function package prefix", setSlice(&calleeOpts.PkgPrefixes))
+	Analyzer.Flags.Func("callee.pkgpath", "callee function package path (exact match)", setSlice(&calleeOpts.PkgPaths))
 
-	Analyzer.Flags.Func("caller.names", "caller function names (comma separated)", setMap(&callerOpts.Names))
+	Analyzer.Flags.Func("caller.names", "caller function names (comma separated, Func, pkg/path.Func, pkg/path.Type.Method or (*pkg/path.Type).Method)", setMap(&callerOpts.Names))
+	Analyzer.Flags.StringVar(&callerOpts.NamesRegex, "caller.names.regex", "", "regular expression matching caller function names")
+	Analyzer.Flags.Func("caller.names.glob", "glob patterns matching caller function names (comma separated)", setSlice(&callerOpts.NamesGlobs))
+	Analyzer.Flags.Func("caller.implements", "select caller methods of types implementing interfaces (comma separated, pkg/path.Interface)", setSlice(&callerOpts.Implements))
 	Analyzer.Flags.Func("caller.params", "caller function params (comma separated, in order)", setSlice(&callerOpts.Params))
 	Analyzer.Flags.Func("caller.results", "caller function results (comma separated, in order)", setSlice(&callerOpts.Results))
 	Analyzer.Flags.Func("caller.pkg", "caller function package prefix", setSlice(&callerOpts.PkgPrefixes))
@@ -54,21 +76,109 @@ func setMap(o *map[string]struct{}) func(string) error {
 	}
 }
 
+func setMode(o *Mode) func(string) error {
+	return func(s string) error {
+		switch m := Mode(s); m {
+		case "", ModeRequire, ModeForbid, ModeAllPaths, ModeOrder, ModePair:
+			*o = m
+			return nil
+		default:
+			return fmt.Errorf("unknown mode %q", s)
+		}
+	}
+}
+
 var (
 	opts       Opts
 	callerOpts CallerOpts
 	calleeOpts CalleeOpts
 )
 
+// Mode decides what is reported for a caller.
+type Mode string
+
+const (
+	// ModeRequire reports callers that do not call the callee.
+	// This is the default.
+	ModeRequire Mode = "require"
+
+	// ModeForbid reports callers that call the callee.
+	ModeForbid Mode = "forbid"
+
+	// ModeAllPaths reports returns of callers that can be reached
+	// without calling the callee.
+	ModeAllPaths Mode = "allpaths"
+
+	// ModeOrder reports calls of guarded functions in callers,
+	// which can be reached without calling the callee first.
+	ModeOrder Mode = "order"
+
+	// ModePair reports calls in callers reaching the callee (acquire),
+	// which are not followed by a call reaching a release function.
+	ModePair Mode = "pair"
+)
+
 type Opts struct {
+	// Path to a JSON file containing additional rules, see [FileConfig].
+	ConfigFile string
+
+	// Rule mode, defaults to [ModeRequire].
+	Mode Mode
+
+	// Name of the guarded function, used by [ModeOrder].
+	Guarded string
+
+	// Names of the release functions, used by [ModePair].
+	Release []string
+
 	// Skip callers and callees in all files with specified suffixes.
 	SkipFileSuffixes []string
+
+	// Report the call path from every caller that calls the callee.
+	ReportPaths bool
+
+	// How the callee has to be called, defaults to [RequireAny].
+	Require Require
+
+	// Calls in go statements don't reach the callee, see [Rule.IgnoreGo].
+	IgnoreGo bool
+
+	// Functions sent over channels don't reach the callee, see [Rule.IgnoreChannels].
+	IgnoreChannels bool
+
+	// Path to a JSON file containing known findings, see [Baseline].
+	BaselineFile string
+
+	// Algorithm building the call graph, defaults to [CallGraphVTA].
+	CallGraph CallGraph
+
+	// Treatment of calls through reflection, which can't be resolved, defaults to [ReflectionIgnore].
+	Reflection Reflection
+
+	// Write all findings to the baseline file instead of reporting them.
+	UpdateBaseline bool
+
+	// Packages whose findings are written to the baseline file, see [Config.BaselinePackages].
+	BaselinePackages []string
 }
 
 type CallerOpts struct {
 	// Function names to search for.
+	// Qualified names like path/to/pkg.Func or (*path/to/pkg.Type).Method are supported.
 	Names map[string]struct{}
 
+	// Regular expression matching function names to search for.
+	// Closures are never matched by patterns.
+	NamesRegex string
+
+	// Glob patterns ([path.Match]) matching function names to search for.
+	NamesGlobs []string
+
+	// Qualified interface names (path/to/pkg.Name).
+	// Methods of the interface are searched for,
+	// if their receiver implements the interface.
+	Implements []string
+
 	// Callers in a package not containing a prefix are skipped.
 	PkgPrefixes []string
 
@@ -77,15 +187,24 @@ type CallerOpts struct {
 
 	// Types of the function's results (return types).
 	Results []string
+
+	// Only functions annotated with a //sadboy:rule or //sadboy:require directive are callers,
+	// see [directivePrefix] for analyzers not named sadboy.
+	// Annotated functions are callers regardless of this option.
+	OnlyDirectives bool
 }
 
 type CalleeOpts struct {
 	// Function name to search for.
+	// Qualified names like path/to/pkg.Func or (*path/to/pkg.Type).Method are supported.
 	Name string
 
 	// Callees in a package not containing a prefix are skipped.
 	PkgPrefixes []string
 
+	// Callees in a package not matching a path exactly are skipped.
+	PkgPaths []string
+
 	// Types of the function's parameters.
 	Params []string
 
@@ -93,110 +212,168 @@ type CalleeOpts struct {
 	Results []string
 }
 
-type typesFact struct {
-	typesInfo *types.Info
+// Analyzer is configured by flags, see [SetOpts] for configuring it in tests.
+// Use [New] to create analyzers with their own configuration.
+// Drivers should validate the configuration with [FlagConfig] once the flags are parsed,
+// otherwise an invalid configuration fails every package.
+// The configuration is compiled once, not for every package.
+var Analyzer, AnalyzerHasCaller = newAnalyzer("sadboy", flagRules, func() Config {
+	// Errors are reported by flagRules.
+	cfg, _ := FlagConfig()
+	return cfg
+})
+
+// New creates an analyzer checking the rules in cfg.
+// Analyzers created by New are independent of each other and of [Analyzer].
+func New(cfg Config) *analysis.Analyzer {
+	name := cfg.Name
+	if name == "" {
+		name = "sadboy"
+	}
+	cfg.Rules = slices.Clone(cfg.Rules)
+	a, _ := newAnalyzer(name, sync.OnceValues(cfg.compile), func() Config {
+		return cfg
+	})
+	return a
 }
 
-func (*typesFact) AFact() {}
+// checker holds the configuration of an analyzer and its pre scan.
+type checker struct {
+	// rules returns the rules to check.
+	rules func() ([]*rule, error)
 
-func (*typesFact) String() string {
-	return "types"
-}
+	// settings returns the configuration of the analyzer, its rules are ignored.
+	settings func() Config
 
-var Analyzer = &analysis.Analyzer{
-	Name: "sadboy",
-	Doc:  "checks if there exists a call path between caller and callee",
-	Run:  run,
-	Requires: []*analysis.Analyzer{
-		AnalyzerHasCaller,
-	},
-	FactTypes: []analysis.Fact{
-		&typesFact{},
-	},
+	analyzer  *analysis.Analyzer
+	hasCaller *analysis.Analyzer
 }
 
-func run(pass *analysis.Pass) (interface{}, error) {
+// newAnalyzer returns an analyzer and the pre scan analyzer it requires.
+func newAnalyzer(name string, rules func() ([]*rule, error), settings func() Config) (*analysis.Analyzer, *analysis.Analyzer) {
+	c := newChecker(name, rules, settings)
+	return c.analyzer, c.hasCaller
+}
 
-	preScanRes, ok := pass.ResultOf[AnalyzerHasCaller].(*preScanResult)
-	if !ok {
-		panic("no pre scan result")
+// newChecker creates a checker with its analyzer and pre scan analyzer.
+func newChecker(name string, rules func() ([]*rule, error), settings func() Config) *checker {
+	c := &checker{rules: rules, settings: settings}
+	c.hasCaller = &analysis.Analyzer{
+		Name: name + "_hascaller",
+		Doc:  "checks if the package contains any callers",
+		Run:  c.runHasCallers,
+		Requires: []*analysis.Analyzer{
+			inspect.Analyzer,
+		},
+		ResultType: reflect.TypeOf(new(preScanResult)),
 	}
+	c.analyzer = &analysis.Analyzer{
+		Name: name,
+		Doc:  "checks if there exists a call path between caller and callee",
+		Run:  c.run,
+		Requires: []*analysis.Analyzer{
+			c.hasCaller,
+		},
+		FactTypes: []analysis.Fact{
+			&summaryFact{},
+		},
+	}
+	return c
+}
 
-	// Export type info of the current package.
-	fact := typesFact{pass.TypesInfo}
-	pass.ExportPackageFact(&fact)
+func (c *checker) run(pass *analysis.Pass) (interface{}, error) {
 
-	if !preScanRes.hasCaller {
-		return nil, nil
+	preScanRes, ok := pass.ResultOf[c.hasCaller].(*preScanResult)
+	if !ok {
+		panic("no pre scan result")
 	}
 
-	// Build program for current package first.
-
+	// Build the SSA of the current package (copied from buildssa.Analyzer).
+	// Imported packages are created from type information only,
+	// calls to their functions are resolved using their summaries.
 	prog := ssa.NewProgram(pass.Fset, ssa.InstantiateGenerics)
 
 	seen := make(map[*types.Package]struct{})
-
-	// Add imported packages to the program.
-	// This is similar to what buildssa.Analyzer does, but we also build imported packages.
-	var addImports func(pp []*types.Package, depth int)
-	addImports = func(pp []*types.Package, depth int) {
-		//if depth > 2 {
-		//	return
-		//}
-
-		for _, p := range pp {
+	var createAll func(pkgs []*types.Package)
+	createAll = func(pkgs []*types.Package) {
+		for _, p := range pkgs {
 			if _, ok := seen[p]; ok {
 				continue
 			}
 			seen[p] = struct{}{}
-
-			tfact := &typesFact{}
-			// To build the imported package, we need it's type info.
-			if ok := pass.ImportPackageFact(p, tfact); !ok {
-				// No data. Create as dummy.
-				//pass.Reportf(0, "%s :: %s is dummy", pass.Pkg.Path(), p.Path())
-				pkg := prog.CreatePackage(p, nil, nil, true)
-				pkg.Build()
-				continue
-			}
-
-			// Get package files from type info.
-			files := make([]*ast.File, len(tfact.typesInfo.FileVersions))
-			i := 0
-			for f := range tfact.typesInfo.FileVersions {
-				files[i] = f
-				i++
-			}
-			// Add package to program.
-			//pass.Reportf(0, "%s :: importing %s", pass.Pkg.Path(), p.Path())
-			pkg := prog.CreatePackage(p, files, tfact.typesInfo, true)
-
-			// Add imports of the imported package.
-			addImports(p.Imports(), depth+1)
-
-			pkg.Build()
+			prog.CreatePackage(p, nil, nil, true)
+			createAll(p.Imports())
 		}
 	}
-	addImports(pass.Pkg.Imports(), 0)
-	//return nil, nil
+	createAll(pass.Pkg.Imports())
 
-	// Create and build the primary package (copied from buildssa.Analyzer).
 	prog.CreatePackage(pass.Pkg, pass.Files, pass.TypesInfo, false)
 	prog.Build()
 
 	progFns := ssautil.AllFunctions(prog)
 
-	callerFns := make(map[*ssa.Function]struct{})
+	// Build call graph, RTA is rooted at all functions of the package.
+	// Generic functions are only analyzed as instances.
+	var roots []*ssa.Function
 	for fn := range progFns {
-		// Root node.
-		if fn == nil {
-			continue
+		if fn != nil && fn.Pkg != nil && fn.Pkg.Pkg == pass.Pkg && fn.TypeParams().Len() == 0 {
+			roots = append(roots, fn)
 		}
+	}
+	cg, err := buildCallGraph(c.settings().CallGraph, prog, progFns, roots)
+	if err != nil {
+		return nil, err
+	}
 
-		if fn.Pkg == nil || fn.Pkg.Pkg != pass.Pkg {
-			continue
+	//pass.Reportf(1, "call graph for %s:\n%s", pass.Pkg.Path(), cgToString(cg))
+
+	// Deleting synthetic nodes would remove calls to functions outside of the package.
+	//cg.DeleteSyntheticNodes()
+
+	s := &searcher{
+		pass:       pass,
+		cg:         cg,
+		sums:       newSummaries(pass),
+		memo:       newMemo(),
+		reflection: c.settings().Reflection,
+		synthetic:  make(map[*callgraph.Edge]struct{}),
+	}
+
+	// Summarize the package for importing packages.
+	// Sorted, so the summaries don't depend on map order.
+	fns := make([]*ssa.Function, 0, len(progFns))
+	for fn := range progFns {
+		if fn != nil && fn.Pkg != nil && fn.Pkg.Pkg == pass.Pkg {
+			fns = append(fns, fn)
 		}
+	}
+	slices.SortFunc(fns, func(a, b *ssa.Function) int {
+		return cmp.Compare(a.Pos(), b.Pos())
+	})
+	pass.ExportPackageFact(s.summarize(fns, preScanRes.all))
+
+	return nil, c.check(s, preScanRes, fns)
+}
+
+// check evaluates the rules on the functions fns of the package of s.
+func (c *checker) check(s *searcher, preScanRes *preScanResult, fns []*ssa.Function) error {
+	pass := s.pass
+	for _, d := range preScanRes.invalid {
+		pass.Report(d)
+	}
+
+	bl, err := c.loadBaseline()
+	if err != nil {
+		return err
+	}
+	rep := newReporter(pass, preScanRes.all, bl)
 
+	if len(preScanRes.rules) == 0 {
+		return rep.finish()
+	}
+
+	callerFns := make(map[*rule][]*ssa.Function, len(preScanRes.rules))
+	for _, fn := range fns {
 		// Skip synthetic functions.
 		// These could match signatures of the target callers,
 		// and therefore cause early termination of the search.
@@ -204,84 +381,188 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			continue
 		}
 
-		// Check if file should be skipped.
-		// We don't actually skip here, since we need to keep track of all caller functions (even the ones skipped).
-		// If we would skip here, we might end up in weird places in the call graph when following the caller forever.
-		// This is probably not required for all code bases.
-		file := prog.Fset.File(fn.Pos())
-		var skip bool
-		if file != nil {
-			fileName := file.Name()
-			for i := len(opts.SkipFileSuffixes) - 1; i >= 0; i-- {
-				if strings.HasSuffix(fileName, opts.SkipFileSuffixes[i]) {
-					skip = true
-					break
-				}
-			}
+		var fileName string
+		if file := pass.Fset.File(fn.Pos()); file != nil {
+			fileName = file.Name()
 		}
 
-		// Check if function signature matches caller.
-		if !chkSig(fn.Signature, callerOpts.Params, callerOpts.Results) {
-			continue
+		// Functions annotated with directives are callers,
+		// even if they are in a skipped file.
+		var annotated []*rule
+		if obj, ok := fn.Object().(*types.Func); ok {
+			annotated = preScanRes.directives[obj]
+		}
+		for _, r := range annotated {
+			callerFns[r] = append(callerFns[r], fn)
 		}
 
-		// If name is specified, all callers must match.
-		if len(callerOpts.Names) > 0 {
-			if _, ok := callerOpts.Names[fn.Name()]; !ok {
+		for _, r := range preScanRes.rules {
+			// Check if function matches caller.
+			if slices.Contains(annotated, r) || !r.isCaller(pass.Pkg, fn.Name(), fn.Signature) {
 				continue
 			}
-		}
 
-		// Skip if file should be skipped.
-		if skip {
-			continue
-		}
+			// Check if file should be skipped.
+			// We don't actually skip earlier, since we need to keep track of all caller functions (even the ones skipped).
+			// If we would skip earlier, we might end up in weird places in the call graph when following the caller forever.
+			// This is probably not required for all code bases.
+			if skipFile(fileName, r.SkipFileSuffixes) {
+				continue
+			}
 
-		// Record target caller.
-		callerFns[fn] = struct{}{}
+			// Record target caller.
+			callerFns[r] = append(callerFns[r], fn)
+		}
 	}
 
 	//pass.Reportf(1, "%s :callers %d", pass.Pkg.Path(), len(callerFns))
-	if len(callerFns) == 0 {
-		return nil, nil
+
+	// Evaluate rules in order, and callers in source order.
+	for _, r := range preScanRes.rules {
+		slices.SortFunc(callerFns[r], func(a, b *ssa.Function) int {
+			return cmp.Compare(a.Pos(), b.Pos())
+		})
+	}
+	s.prefetch(preScanRes.rules, callerFns)
+	for _, r := range preScanRes.rules {
+		r.check(s, rep, callerFns[r])
 	}
 
-	// Build call graph.
-	// No need to do CHA first.
-	cg := vta.CallGraph(progFns, nil)
+	return rep.finish()
+}
 
-	//pass.Reportf(1, "call graph for %s:\n%s", pass.Pkg.Path(), cgToString(cg))
+// loadBaseline returns the baseline of the analyzer, or nil if it has none.
+func (c *checker) loadBaseline() (*baseline, error) {
+	cfg := c.settings()
+	path, update := cfg.Baseline, cfg.UpdateBaseline
+	switch {
+	case path == "":
+		return nil, nil
+	case update:
+		bl := &baseline{path: path, update: true}
+		if len(cfg.BaselinePackages) > 0 {
+			bl.packages = make(map[string]struct{}, len(cfg.BaselinePackages))
+			for _, pkg := range cfg.BaselinePackages {
+				bl.packages[pkg] = struct{}{}
+			}
+		}
+		return bl, nil
+	default:
+		return loadBaselineCached(path)
+	}
+}
 
-	// Deleting synthetic nodes would remove calls to functions outside of the package.
-	//cg.DeleteSyntheticNodes()
+// check reports all callers violating the rule.
+func (r *rule) check(s *searcher, rep *reporter, callers []*ssa.Function) {
+	for _, caller := range callers {
+		switch r.Mode {
+		case ModeAllPaths:
+			r.checkAllPaths(s, rep, caller)
+			continue
+		case ModeOrder:
+			r.checkOrder(s, rep, caller)
+			continue
+		case ModePair:
+			r.checkPair(s, rep, caller)
+			continue
+		}
 
-	foundCallers := make(map[*ssa.Function]struct{}, len(callerFns))
-	for caller := range callerFns {
-		path := PathSearch(pass, cg.Nodes[caller], func(n *callgraph.Node) bool {
-			// Check if function name matches callee.
-			if n.Func.Name() != calleeOpts.Name {
-				return false
+		path := s.search(s.node(caller), r)
+		if path == nil {
+			if path := s.searchReflection(s.node(caller), r); path != nil {
+				reach := s.reach(caller, path, r, s.pass.Pkg)
+				rep.reportFinding(r, caller, pathDiagnostic(s.pass.Pkg, caller, path, reach, r.message(r.reflectMsg, MessageData{
+					Caller: caller.Name(),
+					Callee: r.Callee.Name,
+				})))
+				continue
 			}
-			// Check if function signature matches callee.
-			if !chkSig(n.Func.Signature, calleeOpts.Params, calleeOpts.Results) {
-				return false
+			if r.Mode != ModeForbid {
+				rep.reportFinding(r, caller, analysis.Diagnostic{
+					Pos:     caller.Pos(),
+					Message: r.message(r.msg, MessageData{Caller: caller.Name(), Callee: r.Callee.Name}),
+				})
 			}
+			continue
+		}
 
-			//pass.Reportf(1, "found callee: %s -> %s", caller.RelString(nil), n.Func.RelString(nil))
-			return true
-		})
-		if path != nil {
-			foundCallers[caller] = struct{}{}
+		reach := s.reach(caller, path, r, s.pass.Pkg)
+		data := MessageData{Caller: caller.Name(), Callee: reach.Callee}
+		if r.Flow != nil && s.flowSearch(caller, []int{r.Flow.Param}, r) == nil {
+			// Report the path without the flow as witness.
+			rep.reportFinding(r, caller, pathDiagnostic(s.pass.Pkg, caller, path, reach, r.message(r.flowMsg, data)))
+			continue
+		}
+		switch {
+		case r.Mode == ModeForbid:
+			rep.reportFinding(r, caller, pathDiagnostic(s.pass.Pkg, caller, path, reach, r.message(r.msg, data)))
+		case r.reportPaths:
+			rep.report(r, pathDiagnostic(s.pass.Pkg, caller, path, reach, r.message(r.foundMsg, data)))
 		}
 	}
+}
+
+// isCaller returns true if the function name in package pkg with signature sig
+// matches the callers of the rule.
+func (r *rule) isCaller(pkg *types.Package, name string, sig *types.Signature) bool {
+	// Only annotated functions are callers.
+	if r.Caller.OnlyDirectives {
+		return false
+	}
+
+	// Check if function signature matches caller.
+	if !chkSig(sig, r.Caller.Params, r.Caller.Results) {
+		return false
+	}
 
-	for fn := range callerFns {
-		if _, ok := foundCallers[fn]; !ok {
-			pass.Reportf(fn.Pos(), "%s does not call callee function", fn.Name())
+	// Check if function package matches caller.
+	if !checkPkg(pkg, r.Caller.PkgPrefixes) {
+		return false
+	}
+
+	// If names are specified, all callers must match one of them.
+	if len(r.callers) == 0 && r.callersRegex == nil && len(r.Caller.NamesGlobs) == 0 && len(r.Caller.Implements) == 0 {
+		return true
+	}
+	if slices.ContainsFunc(r.callers, func(sel funcSelector) bool {
+		return sel.match(pkg, name, sig)
+	}) {
+		return true
+	}
+	// Patterns only match named functions, not their closures (Func$1).
+	if strings.Contains(name, "$") {
+		return false
+	}
+	if r.callersRegex != nil && r.callersRegex.MatchString(name) {
+		return true
+	}
+	for _, pattern := range r.Caller.NamesGlobs {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
 		}
 	}
+	if len(r.Caller.Implements) > 0 && r.implements(pkg, name, sig) {
+		return true
+	}
+	return false
+}
 
-	return nil, nil
+// isCallee returns true if fn matches the callee of the rule.
+func (r *rule) isCallee(fn *ssa.Function) bool {
+	// Check if function name matches callee.
+	if !r.callee.matchFunc(fn) {
+		return false
+	}
+	// Check if function package matches callee.
+	pkg := funcPkg(fn)
+	if !checkPkg(pkg, r.Callee.PkgPrefixes) || !checkPkgPath(pkg, r.Callee.PkgPaths) {
+		return false
+	}
+	// Check if function signature matches callee.
+	if !chkSig(fn.Signature, r.Callee.Params, r.Callee.Results) {
+		return false
+	}
+	return true
 }
 
 // PathSearch finds an arbitrary path starting at node start and
@@ -289,155 +570,109 @@ func run(pass *analysis.Pass) (interface{}, error) {
 // PathSearch returns the path as an ordered list of edges; on
 // failure, it returns nil.
 //
+// Only feasible paths are returned, see [searcher.feasible].
+//
 // copied and modified from [callgraph.PathSearch].
 func PathSearch(pass *analysis.Pass, start *callgraph.Node, isEnd func(*callgraph.Node) bool) []*callgraph.Edge {
-	stack := make([]*callgraph.Edge, 0, 32)
-	seen := make(map[*callgraph.Node]struct{})
-
-	// Check if caller has a function param,
-	// and see if it's actually called at this site.
-	//
-	// This is required because the call graph contains all possible
-	// calls, and we need to find the correct edge for this call.
-	//
-	// Example:
-	// func A(a func(){}) { a() }
-	//
-	// A(B)
-	//
-	// `a` will have two outgoing edges to `B` and to `C`,
-	// but we don't know which one it is by just looking at the call site `a()`.
-	// We also need to check the incoming edge `inc` to this call site.
-	var isFakeCall func(inc *callgraph.Edge, e *callgraph.Edge) bool
-	isFakeCall = func(inc *callgraph.Edge, e *callgraph.Edge) bool {
-		// e.Caller.Func would be `A` in the example above.
-		callerParams := e.Caller.Func.Params
-		// Caller has no params, nothing to do.
-		if len(callerParams) == 0 {
-			//pass.Reportf(e.Site.Pos(), "caller has no params")
-			return false
-		}
-
-		common := inc.Site.Common()
-		var hasFunc bool
-
-		var paramIdx int
-		var argIdx int
-		if common.IsInvoke() {
-			paramIdx = 1
-		}
-		for ; paramIdx < len(callerParams); paramIdx, argIdx = paramIdx+1, argIdx+1 {
-			param := callerParams[paramIdx]
-			// Check if param is a function.
-			if _, ok := param.Type().Underlying().(*types.Signature); ok {
-				hasFunc = true
-
-				//if len(callerParams) != len(common.Args) {
-				//	panic(fmt.Sprintf("\n"+
-				//		"callerCallSite: %s\n"+
-				//		"caller1: %s\n"+
-				//		"caller2: %s\n"+
-				//		"callerParams: %s\n"+
-				//		"param[%d]: %s\n"+
-				//		"commonArgs: %s\n"+
-				//		"isInvoke: %t\n",
-				//		inc.Site, inc.Callee.Func, e.Caller.Func, callerParams, i, param, common.Args, common.IsInvoke(),
-				//	))
-				//}
-
-				arg := common.Args[argIdx]
-
-				var incCallParam *ssa.Function
-				switch arg := arg.(type) {
-				case *ssa.Function:
-					incCallParam = arg
-				case *ssa.MakeClosure:
-					// TODO: make test case for this and confirm this is correct
-					incCallParam = arg.Fn.(*ssa.Function)
-				case *ssa.Parameter:
-					// Arg is passed down as a param.
-					//pass.Reportf(e.Site.Pos(), "param [%s] is arg #%d [%s]", param.Name(), argIdx, arg.Name())
-					return false
-				default:
-					//pass.Reportf(e.Site.Pos(), "unknown arg type: %T", arg)
-				}
-
-				// Check if incomming call param and arg are the same function.
-				// (a == B in the example above)
-				if incCallParam == e.Callee.Func {
-					//pass.Reportf(e.Site.Pos(), "param [%s] is called", param.Name())
-					return false
-				}
-
-				// Check if param is passed down as an arg.
-				for _, cArg := range e.Site.Common().Args {
-					if param.Name() == cArg.Name() {
-						//pass.Reportf(
-						//	e.Site.Pos(),
-						//	"param [%s] is arg with same name [%s]",
-						//	param.Name(), cArg.Name(),
-						//)
-						return false
-					}
-
-					// case *ssa.Parameter should be covered by the name match above.
-					var outCallParam *ssa.Function
-					switch arg := cArg.(type) {
-					case *ssa.Function:
-						outCallParam = arg
-					case *ssa.MakeClosure:
-						outCallParam = arg.Fn.(*ssa.Function)
-					}
-
-					// A(func(){})
-					if incCallParam == outCallParam {
-						//pass.Reportf(e.Site.Pos(), "param [%s] is arg with diff name [%s]", param.Name(), cArg.Name())
-						return false
-					}
-				}
-
-				// param is neither called nor passes as an arg.
-				//pass.Reportf(e.Site.Pos(), "param [%s] neither called nor passed as an arg", param.Name())
-				return true
-			}
-		}
-
-		//if !hasFunc {
-		//	pass.Reportf(e.Site.Pos(), "pass has func")
-		//}
+	s := &searcher{pass: pass}
+	return s.pathSearch(start, func(n *callgraph.Node, _ []*callgraph.Edge) bool {
+		return isEnd(n)
+	}, nil, nil, nil)
+}
 
-		//pass.Reportf(e.Site.Pos(), "call has no func param %t", hasFunc)
-		return hasFunc
+// pathSearch is [PathSearch], but additionally follows the edges returned by expand.
+// isEnd and expand are called with the current node and the path up to it.
+// Nodes for which prune returns true and edges for which skip returns true are not searched,
+// prune and skip may be nil.
+//
+// The call graph contains all possible calls of a call site.
+// A function value or interface passed as argument is called by every call site
+// the argument flows into, so the callees of such a call site depend on the path leading to it.
+// Nodes are therefore visited once per context, see [searcher.context],
+// and once per path with and without deferred calls, see [Require].
+func (s *searcher) pathSearch(start *callgraph.Node, isEnd func(n *callgraph.Node, stack []*callgraph.Edge) bool, prune func(*callgraph.Node) bool, skip func(*callgraph.Edge) bool, expand func(n *callgraph.Node, stack []*callgraph.Edge) []*callgraph.Edge) []*callgraph.Edge {
+	type visit struct {
+		node     *callgraph.Node
+		ctx      string
+		deferred bool
 	}
+	stack := make([]*callgraph.Edge, 0, 32)
+	seen := make(map[visit]struct{})
+
 	var search func(n *callgraph.Node) []*callgraph.Edge
 	search = func(n *callgraph.Node) []*callgraph.Edge {
-		if _, ok := seen[n]; !ok {
-			seen[n] = struct{}{}
-			if isEnd(n) {
-				return stack
+		v := visit{n, s.context(n.Func, stack), isDeferred(stack)}
+		if _, ok := seen[v]; ok {
+			return nil
+		}
+		seen[v] = struct{}{}
+		if isEnd(n, stack) {
+			return stack
+		}
+		if prune != nil && prune(n) {
+			return nil
+		}
+		out := n.Out
+		if expand != nil {
+			out = append(slices.Clip(out), expand(n, stack)...)
+		}
+		for _, e := range out {
+			if (skip != nil && skip(e)) || !s.feasible(e, stack) {
+				continue
 			}
-			for _, e := range n.Out {
-				// TODO: check len(n.Out) and only call isFakeCall if len(n.Out) > 1 ??
-				if len(stack) > 0 && isFakeCall(stack[len(stack)-1], e) {
-					//pass.Reportf(e.Site.Pos(),
-					//	"fake call: %s -> %s",
-					//	stack[len(stack)-1].Site,
-					//	e.Site,
-					//)
-					continue
-				}
-				stack = append(stack, e) // push
-				if found := search(e.Callee); found != nil {
-					return found
-				}
-				stack = stack[:len(stack)-1] // pop
+			stack = append(stack, e) // push
+			if found := search(e.Callee); found != nil {
+				return found
 			}
+			stack = stack[:len(stack)-1] // pop
 		}
 		return nil
 	}
 	return search(start)
 }
 
+// pathDiagnostic returns a diagnostic of msg followed by the call path from caller to the callee.
+// Every call site on the path is attached as related information,
+// the message contains the same path as a plain text trace,
+// including the part of the path in other packages.
+// Functions are named relative to pkg.
+func pathDiagnostic(pkg *types.Package, caller *ssa.Function, path []*callgraph.Edge, reach reach, msg string) analysis.Diagnostic {
+	related := make([]analysis.RelatedInformation, len(path))
+	for i, e := range path {
+		related[i] = analysis.RelatedInformation{
+			Pos:     e.Pos(),
+			Message: fmt.Sprintf("calls %s", e.Callee.Func.RelString(pkg)),
+		}
+	}
+	return analysis.Diagnostic{
+		Pos:     caller.Pos(),
+		Message: msg + ": " + caller.RelString(pkg) + reach.Trace,
+		Related: related,
+	}
+}
+
+// pathEnd returns the last function on the path starting at caller.
+func pathEnd(caller *ssa.Function, path []*callgraph.Edge) *ssa.Function {
+	if len(path) == 0 {
+		return caller
+	}
+	return path[len(path)-1].Callee.Func
+}
+
+// pathString formats the call path as " -> callee (file:line) -> ...",
+// where file:line is the position of the call site in the calling function.
+func pathString(fset *token.FileSet, from *types.Package, path []*callgraph.Edge) string {
+	var sb strings.Builder
+	for _, e := range path {
+		sb.WriteString(" -> ")
+		sb.WriteString(e.Callee.Func.RelString(from))
+		if pos := fset.Position(e.Pos()); pos.IsValid() {
+			fmt.Fprintf(&sb, " (%s:%d)", filepath.Base(pos.Filename), pos.Line)
+		}
+	}
+	return sb.String()
+}
+
 func debug(condition bool, reportf func()) {
 	if condition {
 		reportf()
@@ -458,6 +693,16 @@ func cgToString(cg *callgraph.Graph) string {
 	return str
 }
 
+// skipFile returns true if fileName has one of the suffixes.
+func skipFile(fileName string, suffixes []string) bool {
+	for i := len(suffixes) - 1; i >= 0; i-- {
+		if strings.HasSuffix(fileName, suffixes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
 // chkPkg returns true if pkg matches a prefix in chk
 // or if either are nil.
 func checkPkg(pkg *types.Package, chk []string) bool {
@@ -522,43 +767,105 @@ func isInit(fn *ssa.Function) bool {
 	return fn.Pkg != nil && fn.Pkg.Func("init") == fn
 }
 
-var AnalyzerHasCaller = &analysis.Analyzer{
-	Name: "sadboy_hascaller",
-	Doc:  "checks if the package contains any callers",
-	Run:  runHasCallers,
-	Requires: []*analysis.Analyzer{
-		inspect.Analyzer,
-	},
-	ResultType: reflect.TypeOf(new(preScanResult)),
+type preScanResult struct {
+	// All rules.
+	all []*rule
+
+	// Rules with callers in the package.
+	rules []*rule
+
+	// Rules of functions annotated with directives.
+	directives map[*types.Func][]*rule
+
+	// Invalid directives.
+	invalid []analysis.Diagnostic
 }
 
-type preScanResult struct {
-	hasCaller bool
+func (res *preScanResult) invalidf(pos token.Pos, format string, args ...any) {
+	res.invalid = append(res.invalid, analysis.Diagnostic{
+		Pos:     pos,
+		Message: fmt.Sprintf(format, args...),
+	})
 }
 
-func runHasCallers(pass *analysis.Pass) (interface{}, error) {
+func (c *checker) runHasCallers(pass *analysis.Pass) (interface{}, error) {
 	inspector := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil)}
 
-	var hasCaller bool
+	rr, err := c.rules()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &preScanResult{
+		all:        rr,
+		directives: make(map[*types.Func][]*rule),
+	}
+
+	hasCaller := make([]bool, len(rr))
 	inspector.Preorder(nodeFilter, func(n ast.Node) {
-		if !hasCaller {
-			fn := n.(*ast.FuncDecl)
-			sig := pass.TypesInfo.TypeOf(fn.Name).(*types.Signature)
+		fn := n.(*ast.FuncDecl)
+		sig := pass.TypesInfo.TypeOf(fn.Name).(*types.Signature)
+
+		for _, d := range parseDirectives(fn.Doc, c.analyzer.Name) {
+			var matched []int
+			switch {
+			case d.verb == "ignore":
+				// Suppressions are handled by the reporter.
+				continue
+			case len(d.args) != 1:
+				res.invalidf(d.pos, "%s directive requires one argument", d.name)
+				continue
+			case d.verb == "rule":
+				for i, r := range rr {
+					if r.Name == d.args[0] {
+						matched = append(matched, i)
+					}
+				}
+			case d.verb == "require":
+				sel, err := parseSelector(d.args[0])
+				if err != nil {
+					res.invalidf(d.pos, "%s directive: %s", d.name, err)
+					continue
+				}
+				for i, r := range rr {
+					if r.requires(sel) {
+						matched = append(matched, i)
+					}
+				}
+			default:
+				res.invalidf(d.pos, "unknown directive %s", d.name)
+				continue
+			}
 
-			nameMatch := true
-			if len(callerOpts.Names) > 0 {
-				_, nameMatch = callerOpts.Names[fn.Name.Name]
+			if len(matched) == 0 {
+				res.invalidf(d.pos, "%s directive: no rule for %s", d.name, d.args[0])
+				continue
 			}
-			if nameMatch &&
-				chkSig(sig, callerOpts.Params, callerOpts.Results) &&
-				checkPkg(pass.Pkg, callerOpts.PkgPrefixes) {
-				hasCaller = true
+			obj := pass.TypesInfo.Defs[fn.Name].(*types.Func)
+			for _, i := range matched {
+				hasCaller[i] = true
+				if !slices.Contains(res.directives[obj], rr[i]) {
+					res.directives[obj] = append(res.directives[obj], rr[i])
+				}
+			}
+		}
+
+		for i, r := range rr {
+			if hasCaller[i] {
+				continue
+			}
+
+			if r.isCaller(pass.Pkg, fn.Name.Name, sig) {
+				hasCaller[i] = true
 			}
 		}
 	})
 
-	return &preScanResult{
-		hasCaller: hasCaller,
-	}, nil
+	for i, r := range rr {
+		if hasCaller[i] {
+			res.rules = append(res.rules, r)
+		}
+	}
+	return res, nil
 }
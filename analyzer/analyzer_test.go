@@ -1,10 +1,18 @@
 package analyzer_test
 
 import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/sollniss/sadboy/analyzer"
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/packages"
 )
 
 func TestCallers(t *testing.T) {
@@ -17,3 +25,682 @@ func TestCallers(t *testing.T) {
 	})()
 	analysistest.Run(t, testdata, analyzer.Analyzer, "callers/...")
 }
+
+func TestReportPaths(t *testing.T) {
+	testdata := analysistest.TestData()
+	defer analyzer.SetOpts(func(o *analyzer.Opts, caller *analyzer.CallerOpts, callee *analyzer.CalleeOpts) {
+		o.ReportPaths = true
+
+		caller.Params = []string{"paths/caller.Param"}
+		caller.Results = []string{"paths/caller.Result"}
+
+		callee.Name = "Callee"
+	})()
+	analysistest.Run(t, testdata, analyzer.Analyzer, "paths/...")
+}
+
+func TestForbid(t *testing.T) {
+	testdata := analysistest.TestData()
+	defer analyzer.SetOpts(func(o *analyzer.Opts, caller *analyzer.CallerOpts, callee *analyzer.CalleeOpts) {
+		o.Mode = analyzer.ModeForbid
+
+		caller.Params = []string{"forbid/caller.Param"}
+		caller.Results = []string{"forbid/caller.Result"}
+
+		callee.Name = "Exit"
+	})()
+	analysistest.Run(t, testdata, analyzer.Analyzer, "forbid/...")
+}
+
+func TestAllPaths(t *testing.T) {
+	testdata := analysistest.TestData()
+	a := analyzer.New(analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "audit",
+				Mode:   analyzer.ModeAllPaths,
+				Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee: analyzer.CalleeOpts{Name: "allpaths/audit.Log"},
+			},
+		},
+	})
+	analysistest.Run(t, testdata, a, "allpaths/...")
+}
+
+func TestOrder(t *testing.T) {
+	testdata := analysistest.TestData()
+	a := analyzer.New(analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:    "authz",
+				Mode:    analyzer.ModeOrder,
+				Caller:  analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee:  analyzer.CalleeOpts{Name: "order/authz.Check"},
+				Guarded: analyzer.CalleeOpts{Name: "order/db.Exec"},
+			},
+		},
+	})
+	analysistest.Run(t, testdata, a, "order/...")
+}
+
+func TestPair(t *testing.T) {
+	testdata := analysistest.TestData()
+	a := analyzer.New(analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "tx",
+				Mode:   analyzer.ModePair,
+				Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee: analyzer.CalleeOpts{Name: "pair/db.Begin"},
+				Release: []analyzer.CalleeOpts{
+					{Name: "(*pair/db.Tx).Commit"},
+					{Name: "(*pair/db.Tx).Rollback"},
+				},
+			},
+		},
+	})
+	analysistest.Run(t, testdata, a, "pair/...")
+}
+
+func TestFlow(t *testing.T) {
+	testdata := analysistest.TestData()
+	a := analyzer.New(analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "audit",
+				Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee: analyzer.CalleeOpts{Name: "flow/audit.Log"},
+				Flow:   &analyzer.Flow{Param: 0, Arg: 0},
+			},
+		},
+	})
+	analysistest.Run(t, testdata, a, "flow/...")
+}
+
+// TestCallGraph documents which callers of testdata/src/callers
+// are reported with each call graph algorithm.
+func TestCallGraph(t *testing.T) {
+	fail := []string{
+		"Test1_fail", "test2_fail", "Test3_fail", "Test4_fail",
+		"Test5_fail", "Test6_fail", "Test7_fail", "Test8_fail",
+		"Reflect1_fail",
+	}
+	tests := []struct {
+		callGraph analyzer.CallGraph
+		want      []string
+	}{
+		// Misses calls of function values (Test5) and interface methods (Test8).
+		{analyzer.CallGraphStatic, append([]string{"Test5", "Test8"}, fail...)},
+		{analyzer.CallGraphCHA, fail},
+		{analyzer.CallGraphRTA, fail},
+		{analyzer.CallGraphVTA, fail},
+		{analyzer.CallGraphVTACHA, fail},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.callGraph), func(t *testing.T) {
+			t.Parallel()
+			a := analyzer.New(analyzer.Config{
+				Rules: []analyzer.Rule{
+					{
+						Name: "sadboy",
+						Caller: analyzer.CallerOpts{
+							Params:  []string{"callers/caller.Param"},
+							Results: []string{"callers/caller.Result"},
+						},
+						Callee: analyzer.CalleeOpts{Name: "Callee"},
+					},
+				},
+				CallGraph: tt.callGraph,
+			})
+
+			// The want comments are for the default algorithm, only compare the reported callers.
+			var got []string
+			for _, res := range analysistest.Run(ignoreWants{}, analysistest.TestData(), a, "callers/...") {
+				for _, d := range res.Diagnostics {
+					caller, _, _ := strings.Cut(d.Message, " ")
+					got = append(got, caller)
+				}
+			}
+			slices.Sort(got)
+			want := slices.Sorted(slices.Values(tt.want))
+			if !slices.Equal(got, want) {
+				t.Errorf("reported callers = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestReportOrder checks that callers searched in parallel are reported in source order.
+func TestReportOrder(t *testing.T) {
+	cfg := analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name: "sadboy",
+				Caller: analyzer.CallerOpts{
+					Params:  []string{"callers/caller.Param"},
+					Results: []string{"callers/caller.Result"},
+				},
+				Callee: analyzer.CalleeOpts{Name: "Callee"},
+			},
+		},
+		ReportPaths: true,
+	}
+
+	var first []string
+	for i := range 3 {
+		var got []string
+		for _, res := range analysistest.Run(ignoreWants{}, analysistest.TestData(), analyzer.New(cfg), "callers/...") {
+			if !slices.IsSortedFunc(res.Diagnostics, func(a, b analysis.Diagnostic) int {
+				return cmp.Compare(a.Pos, b.Pos)
+			}) {
+				t.Errorf("%s: diagnostics not in source order", res.Pass.Pkg.Path())
+			}
+			for _, d := range res.Diagnostics {
+				got = append(got, d.Message)
+			}
+		}
+		if i == 0 {
+			first = got
+		} else if !slices.Equal(got, first) {
+			t.Errorf("run %d: diagnostics = %q, want %q", i, got, first)
+		}
+	}
+}
+
+// ignoreWants ignores mismatched want comments in analysistest.Run.
+type ignoreWants struct{}
+
+func (ignoreWants) Errorf(string, ...any) {}
+
+func TestCheckProgram(t *testing.T) {
+	testdata := analysistest.TestData()
+	cfg := analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "audit",
+				Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee: analyzer.CalleeOpts{Name: "program/audit.Log"},
+			},
+		},
+	}
+
+	// The methods iface.Use invokes on its parameter are resolved by its summary.
+	var got []string
+	for _, res := range analysistest.Run(ignoreWants{}, testdata, analyzer.New(cfg), "program/api") {
+		for _, d := range res.Diagnostics {
+			got = append(got, d.Message)
+		}
+	}
+	want := []string{
+		"HandleNone does not call callee function",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("analyzer diagnostics = %q, want %q", got, want)
+	}
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: analyzer.LoadMode,
+		Dir:  filepath.Join(testdata, "src", "program"),
+	}, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diags, err := analyzer.CheckProgram(cfg, pkgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%s:%d: %s", filepath.Base(d.Position.Filename), d.Position.Line, d.Message))
+	}
+	want = []string{
+		"api.go:12: HandleNone does not call callee function",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("program diagnostics = %q, want %q", got, want)
+	}
+}
+
+// TestCheckProgramBaseline checks that the whole program analysis writes
+// the findings of the loaded packages to the baseline.
+func TestCheckProgramBaseline(t *testing.T) {
+	testdata := analysistest.TestData()
+	path := filepath.Join(t.TempDir(), "sadboy-baseline.json")
+	cfg := analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "audit",
+				Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee: analyzer.CalleeOpts{Name: "program/audit.Log"},
+			},
+		},
+		Baseline:       path,
+		UpdateBaseline: true,
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: analyzer.LoadMode,
+		Dir:  filepath.Join(testdata, "src", "program"),
+	}, "./api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diags, err := analyzer.CheckProgram(cfg, pkgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) > 0 {
+		t.Errorf("program diagnostics = %v, want none", diags)
+	}
+
+	bl, err := analyzer.LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []analyzer.Finding{
+		{Rule: "audit", Package: "program/api", Func: "HandleNone"},
+	}
+	if !slices.Equal(bl.Findings, want) {
+		t.Errorf("baseline findings = %v, want %v", bl.Findings, want)
+	}
+}
+
+// TestFeasible checks that closures and interfaces passed to a library function
+// are only called by the invocations they are passed to,
+// even though the whole program call graph merges them.
+func TestFeasible(t *testing.T) {
+	testdata := analysistest.TestData()
+	cfg := analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "audit",
+				Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee: analyzer.CalleeOpts{Name: "feasible/audit.Log"},
+			},
+		},
+	}
+	analysistest.Run(t, testdata, analyzer.New(cfg), "feasible/...")
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: analyzer.LoadMode,
+		Dir:  filepath.Join(testdata, "src", "feasible"),
+	}, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"HandleApply_fail does not call callee function",
+		"HandleSort_fail does not call callee function",
+		"HandleUse_fail does not call callee function",
+	}
+	for _, callGraph := range []analyzer.CallGraph{analyzer.CallGraphCHA, analyzer.CallGraphVTA, analyzer.CallGraphVTACHA} {
+		cfg.CallGraph = callGraph
+		diags, err := analyzer.CheckProgram(cfg, pkgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, d := range diags {
+			got = append(got, d.Message)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: program diagnostics = %q, want %q", callGraph, got, want)
+		}
+	}
+}
+
+func TestReflection(t *testing.T) {
+	testdata := analysistest.TestData()
+	rules := []analyzer.Rule{
+		{
+			Name:   "audit",
+			Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+			Callee: analyzer.CalleeOpts{Name: "reflection/audit.Log"},
+		},
+	}
+	analysistest.Run(t, testdata, analyzer.New(analyzer.Config{Rules: rules}), "reflection/api")
+	analysistest.Run(t, testdata, analyzer.New(analyzer.Config{
+		Rules:      rules,
+		Reflection: analyzer.ReflectionConservative,
+	}), "reflection/dynamic")
+}
+
+func TestAsync(t *testing.T) {
+	testdata := analysistest.TestData()
+	rule := analyzer.Rule{
+		Name:   "audit",
+		Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+		Callee: analyzer.CalleeOpts{Name: "async/audit.Log"},
+	}
+	for _, cg := range []analyzer.CallGraph{analyzer.CallGraphCHA, analyzer.CallGraphVTA} {
+		analysistest.Run(t, testdata, analyzer.New(analyzer.Config{Rules: []analyzer.Rule{rule}, CallGraph: cg}), "async/api")
+	}
+	rule.IgnoreGo = true
+	analysistest.Run(t, testdata, analyzer.New(analyzer.Config{Rules: []analyzer.Rule{rule}}), "async/gostmt")
+	rule.IgnoreChannels = true
+	analysistest.Run(t, testdata, analyzer.New(analyzer.Config{Rules: []analyzer.Rule{rule}}), "async/strict")
+}
+
+func TestRequire(t *testing.T) {
+	testdata := analysistest.TestData()
+	rule := analyzer.Rule{
+		Name:    "span",
+		Caller:  analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+		Callee:  analyzer.CalleeOpts{Name: "deferred/span.End"},
+		Require: analyzer.RequireDefer,
+	}
+	analysistest.Run(t, testdata, analyzer.New(analyzer.Config{Rules: []analyzer.Rule{rule}}), "deferred/api")
+	rule.Require = analyzer.RequireDirect
+	analysistest.Run(t, testdata, analyzer.New(analyzer.Config{Rules: []analyzer.Rule{rule}}), "deferred/direct")
+	rule.Mode = analyzer.ModeForbid
+	rule.Require = analyzer.RequireDefer
+	analysistest.Run(t, testdata, analyzer.New(analyzer.Config{Rules: []analyzer.Rule{rule}}), "deferred/forbid")
+}
+
+func TestConfig(t *testing.T) {
+	testdata := analysistest.TestData()
+	defer analyzer.SetOpts(func(o *analyzer.Opts, caller *analyzer.CallerOpts, callee *analyzer.CalleeOpts) {
+		o.ConfigFile = filepath.Join(testdata, "src", "config", "sadboy.json")
+	})()
+	analysistest.Run(t, testdata, analyzer.Analyzer, "config/...")
+}
+
+func TestNew(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	forbid := analyzer.New(analyzer.Config{
+		Name: "forbid",
+		Rules: []analyzer.Rule{{
+			Name: "exit",
+			Mode: analyzer.ModeForbid,
+			Caller: analyzer.CallerOpts{
+				Params:  []string{"forbid/caller.Param"},
+				Results: []string{"forbid/caller.Result"},
+			},
+			Callee: analyzer.CalleeOpts{
+				Name: "Exit",
+			},
+		}},
+	})
+
+	rules, err := analyzer.LoadRules(filepath.Join(testdata, "src", "config", "sadboy.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := analyzer.New(analyzer.Config{
+		Name:  "config",
+		Rules: rules,
+	})
+
+	t.Run("forbid", func(t *testing.T) {
+		t.Parallel()
+		analysistest.Run(t, testdata, forbid, "forbid/...")
+	})
+	t.Run("config", func(t *testing.T) {
+		t.Parallel()
+		analysistest.Run(t, testdata, config, "config/...")
+	})
+}
+
+func TestCalleePkg(t *testing.T) {
+	testdata := analysistest.TestData()
+	caller := analyzer.CallerOpts{
+		Params:  []string{"calleepkg/caller.Param"},
+		Results: []string{},
+	}
+	a := analyzer.New(analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:    "audit",
+				Caller:  caller,
+				Callee:  analyzer.CalleeOpts{Name: "Log", PkgPaths: []string{"calleepkg/audit"}},
+				Message: "{{.Caller}}: {{.Rule}}",
+			},
+			{
+				Name:    "commit",
+				Caller:  caller,
+				Callee:  analyzer.CalleeOpts{Name: "(*calleepkg/db.Tx).Commit"},
+				Message: "{{.Caller}}: {{.Rule}}",
+			},
+			{
+				// Lookalike packages ending in calleepkg/audit don't match.
+				Name:    "qualified",
+				Caller:  caller,
+				Callee:  analyzer.CalleeOpts{Name: "calleepkg/audit.Log"},
+				Message: "{{.Caller}}: {{.Rule}}",
+			},
+		},
+	})
+	analysistest.Run(t, testdata, a, "calleepkg/...")
+}
+
+func TestSelector(t *testing.T) {
+	testdata := analysistest.TestData()
+	defer analyzer.SetOpts(func(o *analyzer.Opts, caller *analyzer.CallerOpts, callee *analyzer.CalleeOpts) {
+		caller.Names = map[string]struct{}{
+			"selector/api.Handle":              {},
+			"(*selector/api.Server).ServeHTTP": {},
+			"api.Service.Run":                  {},
+			"selector/api.PtrService.Run":      {},
+		}
+
+		callee.Name = "selector/audit.Log"
+	})()
+	analysistest.Run(t, testdata, analyzer.Analyzer, "selector/...")
+}
+
+func TestCallerNamePatterns(t *testing.T) {
+	testdata := analysistest.TestData()
+	defer analyzer.SetOpts(func(o *analyzer.Opts, caller *analyzer.CallerOpts, callee *analyzer.CalleeOpts) {
+		caller.NamesRegex = "^Handle[A-Z]"
+		caller.NamesGlobs = []string{"?*Command"}
+
+		callee.Name = "Log"
+	})()
+	analysistest.Run(t, testdata, analyzer.Analyzer, "names/...")
+}
+
+func TestCallerImplements(t *testing.T) {
+	testdata := analysistest.TestData()
+	defer analyzer.SetOpts(func(o *analyzer.Opts, caller *analyzer.CallerOpts, callee *analyzer.CalleeOpts) {
+		caller.Implements = []string{"implements/iface.Handler"}
+
+		callee.Name = "implements/iface.Log"
+	})()
+	analysistest.Run(t, testdata, analyzer.Analyzer, "implements/...")
+}
+
+func TestDirectives(t *testing.T) {
+	testdata := analysistest.TestData()
+	caller := analyzer.CallerOpts{
+		OnlyDirectives: true,
+	}
+	a := analyzer.New(analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:    "audit",
+				Caller:  caller,
+				Callee:  analyzer.CalleeOpts{Name: "directive/audit.Log"},
+				Message: "{{.Caller}} does not call {{.Callee}}",
+			},
+			{
+				Name:    "trace",
+				Caller:  caller,
+				Callee:  analyzer.CalleeOpts{Name: "Trace"},
+				Message: "{{.Caller}} does not call {{.Callee}}",
+			},
+			{
+				Name:    "event",
+				Caller:  caller,
+				Callee:  analyzer.CalleeOpts{Name: "Event", PkgPaths: []string{"directive/audit"}},
+				Message: "{{.Caller}} does not call {{.Callee}}",
+			},
+		},
+	})
+	analysistest.Run(t, testdata, a, "directive/...")
+}
+
+// TestScopedDirectives runs two analyzers on the same package,
+// neither reports the directives of the other.
+func TestScopedDirectives(t *testing.T) {
+	testdata := analysistest.TestData()
+	for _, tt := range []struct {
+		cfg  analyzer.Config
+		want []string
+	}{
+		{
+			cfg: analyzer.Config{
+				Name: "audit",
+				Rules: []analyzer.Rule{{
+					Name:   "log",
+					Caller: analyzer.CallerOpts{OnlyDirectives: true},
+					Callee: analyzer.CalleeOpts{Name: "scoped/audit.Log"},
+				}},
+			},
+			want: []string{
+				"HandleLog_fail does not call callee function",
+				"audit:rule directive: no rule for typo",
+			},
+		},
+		{
+			cfg: analyzer.Config{
+				Name: "perm",
+				Rules: []analyzer.Rule{{
+					Name:   "check",
+					Caller: analyzer.CallerOpts{OnlyDirectives: true},
+					Callee: analyzer.CalleeOpts{Name: "scoped/audit.Check"},
+				}},
+			},
+			want: []string{
+				"HandleCheck_fail does not call callee function",
+			},
+		},
+	} {
+		var got []string
+		for _, res := range analysistest.Run(ignoreWants{}, testdata, analyzer.New(tt.cfg), "scoped/api") {
+			for _, d := range res.Diagnostics {
+				got = append(got, d.Message)
+			}
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s diagnostics = %q, want %q", tt.cfg.Name, got, tt.want)
+		}
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	testdata := analysistest.TestData()
+	a := analyzer.New(analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "audit",
+				Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee: analyzer.CalleeOpts{Name: "suppress/audit.Log"},
+			},
+			{
+				Name:   "trace",
+				Caller: analyzer.CallerOpts{OnlyDirectives: true},
+				Callee: analyzer.CalleeOpts{Name: "suppress/audit.Trace"},
+			},
+		},
+	})
+	analysistest.Run(t, testdata, a, "suppress/...")
+}
+
+func TestBaseline(t *testing.T) {
+	testdata := analysistest.TestData()
+	path := filepath.Join(t.TempDir(), "sadboy-baseline.json")
+	bl := `{"findings": [
+		{"rule": "audit", "package": "baseline/api", "func": "HandleKnown"},
+		{"rule": "audit", "package": "baseline/api", "func": "(*Server).HandleKnown"},
+		{"rule": "other", "package": "baseline/api", "func": "HandleNew"}
+	]}`
+	if err := os.WriteFile(path, []byte(bl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := analyzer.New(analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "audit",
+				Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee: analyzer.CalleeOpts{Name: "baseline/api.Log"},
+			},
+		},
+		Baseline: path,
+	})
+	analysistest.Run(t, testdata, a, "baseline/api")
+}
+
+func TestUpdateBaseline(t *testing.T) {
+	testdata := analysistest.TestData()
+	path := filepath.Join(t.TempDir(), "sadboy-baseline.json")
+	stale := `{"findings": [
+		{"rule": "audit", "package": "baseline/legacy", "func": "Removed"},
+		{"rule": "audit", "package": "other", "func": "Kept"}
+	]}`
+	if err := os.WriteFile(path, []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := analyzer.New(analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "audit",
+				Caller: analyzer.CallerOpts{NamesGlobs: []string{"Handle*"}},
+				Callee: analyzer.CalleeOpts{Name: "baseline/api.Log"},
+			},
+		},
+		Baseline:         path,
+		UpdateBaseline:   true,
+		BaselinePackages: []string{"baseline/legacy"},
+	})
+	analysistest.Run(t, testdata, a, "baseline/legacy")
+
+	bl, err := analyzer.LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The dependency baseline/api is analyzed as well, but its findings are not written.
+	want := []analyzer.Finding{
+		{Rule: "audit", Package: "baseline/legacy", Func: "(*Server).HandleB"},
+		{Rule: "audit", Package: "baseline/legacy", Func: "HandleA"},
+		{Rule: "audit", Package: "other", Func: "Kept"},
+	}
+	if !slices.Equal(bl.Findings, want) {
+		t.Errorf("baseline findings = %v, want %v", bl.Findings, want)
+	}
+}
+
+// BenchmarkPkgtest analyzes testdata/src/pkgtest like make c,
+// each package once (analyzer) and all packages as one program (program).
+func BenchmarkPkgtest(b *testing.B) {
+	testdata := analysistest.TestData()
+	cfg := analyzer.Config{
+		Rules: []analyzer.Rule{
+			{
+				Name:   "sadboy",
+				Caller: analyzer.CallerOpts{Results: []string{"pkgtest/pkg3.Return"}},
+				Callee: analyzer.CalleeOpts{Name: "A"},
+			},
+		},
+	}
+	b.Run("analyzer", func(b *testing.B) {
+		a := analyzer.New(cfg)
+		for range b.N {
+			analysistest.Run(ignoreWants{}, testdata, a, "pkgtest/...")
+		}
+	})
+	b.Run("program", func(b *testing.B) {
+		for range b.N {
+			pkgs, err := packages.Load(&packages.Config{
+				Mode: analyzer.LoadMode,
+				Dir:  filepath.Join(testdata, "src", "pkgtest"),
+			}, "./...")
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := analyzer.CheckProgram(cfg, pkgs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
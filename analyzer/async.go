@@ -0,0 +1,123 @@
+package analyzer
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Calls running asynchronously reach the callee:
+// calls in go statements, unless the rule sets [Rule.IgnoreGo],
+// and of functions sent over channels to a worker, which calls the functions
+// received from the channel, unless the rule sets [Rule.IgnoreChannels]:
+//
+//	jobs <- func() { audit.Log() }
+//
+//	for job := range jobs {
+//		job()
+//	}
+//
+// The call graph has no edges from the sender to the functions it sends.
+// They are added by [searcher.sendEdges] during searches.
+
+// received is a call site calling a value received from a channel.
+type received struct {
+	// Type of the channel.
+	chanType types.Type
+
+	site ssa.CallInstruction
+}
+
+// receivedChan returns the channel v was received from, or nil.
+func receivedChan(v ssa.Value) ssa.Value {
+	if ex, ok := v.(*ssa.Extract); ok && ex.Index == 0 {
+		v = ex.Tuple
+	}
+	if recv, ok := v.(*ssa.UnOp); ok && recv.Op == token.ARROW {
+		return recv.X
+	}
+	return nil
+}
+
+// receivers returns all call sites in the call graph calling values received from channels.
+// The call sites are computed once per call graph.
+func (s *searcher) receivers() []received {
+	s.memo.receiversOnce.Do(func() {
+		s.mu.Lock()
+		fns := make([]*ssa.Function, 0, len(s.cg.Nodes))
+		for fn := range s.cg.Nodes {
+			if fn != nil {
+				fns = append(fns, fn)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, fn := range fns {
+			for _, b := range fn.Blocks {
+				for _, instr := range b.Instrs {
+					site, ok := instr.(ssa.CallInstruction)
+					if !ok || site.Common().IsInvoke() {
+						continue
+					}
+					if ch := receivedChan(site.Common().Value); ch != nil {
+						s.memo.receivers = append(s.memo.receivers, received{ch.Type(), site})
+					}
+				}
+			}
+		}
+	})
+	return s.memo.receivers
+}
+
+// sendEdges returns edges from n to the functions n sends over channels,
+// at every call site calling values received from a channel of the same type.
+// The call sites are in the receiving functions, their arguments are passed to the functions.
+// stack is the path leading to n.
+func (s *searcher) sendEdges(n *callgraph.Node, stack []*callgraph.Edge) []*callgraph.Edge {
+	if n.Func == nil {
+		return nil
+	}
+	var out []*callgraph.Edge
+	for _, b := range n.Func.Blocks {
+		for _, instr := range b.Instrs {
+			send, ok := instr.(*ssa.Send)
+			if !ok {
+				continue
+			}
+			var fns []*ssa.Function
+			for _, recv := range s.receivers() {
+				ch, ok := recv.chanType.Underlying().(*types.Chan)
+				if !ok || !types.Identical(ch.Elem(), send.X.Type()) {
+					continue
+				}
+				if fns == nil {
+					if fns, _ = s.funcsOf(send.X, stack); fns == nil {
+						break
+					}
+				}
+				for _, fn := range fns {
+					out = append(out, &callgraph.Edge{
+						Caller: n,
+						Site:   recv.site,
+						Callee: s.node(fn),
+					})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// sendsFuncs returns true if fn sends function values over channels.
+func sendsFuncs(fn *ssa.Function) bool {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if send, ok := instr.(*ssa.Send); ok && isDynamic(send.X.Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
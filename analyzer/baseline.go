@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Baseline is the content of a baseline file.
+// Findings in the baseline are not reported, so sadboy can be enabled
+// on code bases with existing violations.
+type Baseline struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Finding identifies a caller violating a rule.
+type Finding struct {
+	// Rule name.
+	Rule string `json:"rule"`
+
+	// Package path of the caller.
+	Package string `json:"package"`
+
+	// Caller name relative to its package, see [ssa.Function.RelString].
+	Func string `json:"func"`
+}
+
+// LoadBaseline reads the baseline file at path.
+func LoadBaseline(path string) (*Baseline, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bl Baseline
+	if err := json.Unmarshal(b, &bl); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &bl, nil
+}
+
+// baseline is a loaded baseline file.
+type baseline struct {
+	path string
+
+	// Known findings are not reported.
+	known map[Finding]struct{}
+
+	// Update the baseline with the findings of the package instead of reporting them.
+	update bool
+
+	// Packages whose findings are written, see [Config.BaselinePackages].
+	// nil for the packages of the main module.
+	packages map[string]struct{}
+}
+
+// writes returns true if the findings of the package of pass are written to the baseline.
+func (bl *baseline) writes(pass *analysis.Pass) bool {
+	if bl.packages != nil {
+		_, ok := bl.packages[pass.Pkg.Path()]
+		return ok
+	}
+	// Other modules and the standard library have a version or no module.
+	return pass.Module != nil && pass.Module.Path != "" && pass.Module.Version == ""
+}
+
+var baselineCache sync.Map // path -> *baseline
+
+// loadBaselineCached reads the baseline at path once.
+// The baseline is used for every analyzed package.
+func loadBaselineCached(path string) (*baseline, error) {
+	if bl, ok := baselineCache.Load(path); ok {
+		return bl.(*baseline), nil
+	}
+	file, err := LoadBaseline(path)
+	if err != nil {
+		return nil, err
+	}
+	bl := &baseline{
+		path:  path,
+		known: make(map[Finding]struct{}, len(file.Findings)),
+	}
+	for _, f := range file.Findings {
+		bl.known[f] = struct{}{}
+	}
+	baselineCache.Store(path, bl)
+	return bl, nil
+}
+
+var (
+	// baselineMu serializes updates of baseline files within the process.
+	baselineMu sync.Mutex
+
+	// Packages whose findings were written in the process, by baseline path.
+	baselineWritten = make(map[string]map[string]struct{})
+)
+
+// updateBaseline replaces the findings of package pkg in the baseline at path.
+// The file is created if it does not exist.
+//
+// A package and its test variant share the package path,
+// only the first write of a package in the process replaces its findings,
+// the findings of later writes are added.
+func updateBaseline(path, pkg string, findings []Finding) error {
+	baselineMu.Lock()
+	defer baselineMu.Unlock()
+
+	written := baselineWritten[path]
+	if written == nil {
+		written = make(map[string]struct{})
+		baselineWritten[path] = written
+	}
+	_, merge := written[pkg]
+	written[pkg] = struct{}{}
+
+	file, err := LoadBaseline(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		file, err = &Baseline{}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	n := len(file.Findings)
+	file.Findings = slices.DeleteFunc(file.Findings, func(f Finding) bool {
+		return !merge && f.Package == pkg
+	})
+	if n == len(file.Findings) && len(findings) == 0 {
+		// Nothing changed, don't create empty files.
+		return nil
+	}
+	file.Findings = append(file.Findings, findings...)
+	slices.SortFunc(file.Findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.Package, b.Package),
+			cmp.Compare(a.Func, b.Func),
+			cmp.Compare(a.Rule, b.Rule),
+		)
+	})
+	file.Findings = slices.Compact(file.Findings)
+
+	b, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
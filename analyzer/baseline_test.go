@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// A package and its test variant share the package path,
+// the findings of both are kept regardless of which one is written last.
+func TestUpdateBaselineVariants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sadboy-baseline.json")
+	stale := `{"findings": [{"rule": "audit", "package": "p", "func": "Removed"}]}`
+	if err := os.WriteFile(path, []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// p [p.test]
+	if err := updateBaseline(path, "p", []Finding{
+		{Rule: "audit", Package: "p", Func: "HandleA"},
+		{Rule: "audit", Package: "p", Func: "HandleTest"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// p
+	if err := updateBaseline(path, "p", []Finding{
+		{Rule: "audit", Package: "p", Func: "HandleA"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	bl, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Finding{
+		{Rule: "audit", Package: "p", Func: "HandleA"},
+		{Rule: "audit", Package: "p", Func: "HandleTest"},
+	}
+	if !slices.Equal(bl.Findings, want) {
+		t.Errorf("baseline findings = %v, want %v", bl.Findings, want)
+	}
+}
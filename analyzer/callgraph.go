@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/callgraph/static"
+	"golang.org/x/tools/go/callgraph/vta"
+	"golang.org/x/tools/go/ssa"
+)
+
+// CallGraph is the algorithm building the call graph.
+// The algorithms trade precision for speed, see [golang.org/x/tools/go/callgraph].
+type CallGraph string
+
+const (
+	// CallGraphStatic only contains static calls,
+	// calls of function values and interface methods are missing.
+	CallGraphStatic CallGraph = "static"
+
+	// CallGraphCHA (class hierarchy analysis) calls all functions
+	// with a matching signature for dynamic calls.
+	CallGraphCHA CallGraph = "cha"
+
+	// CallGraphRTA (rapid type analysis) only calls functions and types
+	// used by the program, rooted at all functions of the analyzed package.
+	CallGraphRTA CallGraph = "rta"
+
+	// CallGraphVTA (variable type analysis) follows the flow of function values and types.
+	// This is the default.
+	CallGraphVTA CallGraph = "vta"
+
+	// CallGraphVTACHA is VTA refining an initial CHA call graph.
+	CallGraphVTACHA CallGraph = "vta+cha"
+)
+
+func setCallGraph(o *CallGraph) func(string) error {
+	return func(s string) error {
+		switch cg := CallGraph(s); cg {
+		case "", CallGraphStatic, CallGraphCHA, CallGraphRTA, CallGraphVTA, CallGraphVTACHA:
+			*o = cg
+			return nil
+		default:
+			return fmt.Errorf("unknown call graph %q", s)
+		}
+	}
+}
+
+// buildCallGraph builds the call graph of all functions fns of prog.
+// RTA is rooted at roots.
+func buildCallGraph(alg CallGraph, prog *ssa.Program, fns map[*ssa.Function]bool, roots []*ssa.Function) (*callgraph.Graph, error) {
+	switch alg {
+	case CallGraphStatic:
+		return static.CallGraph(prog), nil
+	case CallGraphCHA:
+		return cha.CallGraph(prog), nil
+	case CallGraphRTA:
+		return rta.Analyze(roots, true).CallGraph, nil
+	case "", CallGraphVTA:
+		// No need to do CHA first.
+		return vta.CallGraph(fns, nil), nil
+	case CallGraphVTACHA:
+		return vta.CallGraph(fns, cha.CallGraph(prog)), nil
+	default:
+		return nil, fmt.Errorf("unknown call graph %q", alg)
+	}
+}
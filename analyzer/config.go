@@ -0,0 +1,587 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Config is the configuration of an analyzer created with [New].
+type Config struct {
+	// Name of the analyzer, defaults to "sadboy".
+	Name string
+
+	// Rules to check.
+	Rules []Rule
+
+	// Report the call path from every caller that calls the callee.
+	ReportPaths bool
+
+	// Path to a JSON baseline file, see [Baseline].
+	// Findings in the baseline are not reported.
+	Baseline string
+
+	// Write all findings to the baseline file instead of reporting them.
+	// Each analyzed package replaces its own findings in the file.
+	UpdateBaseline bool
+
+	// Import paths of the packages whose findings are written by UpdateBaseline,
+	// usually the packages requested on the command line.
+	// Dependencies are only analyzed for their summaries.
+	// If empty, the packages of the main module are written,
+	// which is unknown outside of module mode.
+	BaselinePackages []string
+
+	// Algorithm building the call graph, defaults to [CallGraphVTA].
+	CallGraph CallGraph
+
+	// Treatment of calls through reflection, which can't be resolved,
+	// defaults to [ReflectionIgnore].
+	Reflection Reflection
+}
+
+// Validate returns an error if the configuration is invalid,
+// so drivers can report it once before analyzing any package.
+// It checks the same as the analyzer does for every package.
+func (c Config) Validate() error {
+	_, err := c.compile()
+	return err
+}
+
+// compile prepares all rules for evaluation.
+func (c *Config) compile() ([]*rule, error) {
+	if err := setCallGraph(new(CallGraph))(string(c.CallGraph)); err != nil {
+		return nil, err
+	}
+	if err := setReflection(new(Reflection))(string(c.Reflection)); err != nil {
+		return nil, err
+	}
+	compiled := make([]*rule, len(c.Rules))
+	for i := range c.Rules {
+		r, err := compileRule(&c.Rules[i])
+		if err != nil {
+			return nil, err
+		}
+		r.reportPaths = c.ReportPaths
+		compiled[i] = r
+	}
+	return compiled, nil
+}
+
+// Rule is a single invariant between a set of callers and a callee.
+type Rule struct {
+	// Name of the rule, used as the diagnostic category.
+	Name string
+
+	// Rule mode, defaults to [ModeRequire].
+	Mode Mode
+
+	Caller CallerOpts
+	Callee CalleeOpts
+
+	// Guarded functions must not be called before the callee, used by [ModeOrder].
+	Guarded CalleeOpts
+
+	// Release functions, one of which must be called after the callee, used by [ModePair].
+	Release []CalleeOpts
+
+	// Optional parameter of the caller, which must be passed to the callee, used by [ModeRequire].
+	Flow *Flow
+
+	// How the callee has to be called, used by [ModeRequire] and [ModeForbid].
+	// Defaults to [RequireAny].
+	Require Require
+
+	// Skip callers in all files with specified suffixes.
+	SkipFileSuffixes []string
+
+	// Calls in go statements don't reach the callee.
+	IgnoreGo bool
+
+	// Functions sent over channels to a worker don't reach the callee,
+	// see [searcher.sendEdges]. Independent of IgnoreGo, which also ignores
+	// a worker calling the functions in go statements.
+	IgnoreChannels bool
+
+	// Severity is prepended to the message of every diagnostic of the rule.
+	Severity string
+
+	// Message is a [text/template] for the diagnostic message.
+	// The template is executed with [MessageData].
+	// If empty, a default message depending on the mode is used.
+	Message string
+}
+
+// MessageData is passed to the message template of a [Rule].
+type MessageData struct {
+	// Rule name.
+	Rule string
+
+	// Name of the caller function.
+	Caller string
+
+	// Name of the callee function.
+	Callee string
+
+	// Name of the guarded function called before the callee, in [ModeOrder].
+	Guarded string
+
+	// Names of the release functions separated by |, in [ModePair].
+	Release string
+}
+
+const (
+	defaultRequireMessage  = "{{.Caller}} does not call callee function"
+	defaultDeferMessage    = "{{.Caller}} does not defer callee function"
+	defaultDirectMessage   = "{{.Caller}} does not call callee function without defer"
+	defaultForbidMessage   = "{{.Caller}} calls forbidden function {{.Callee}}"
+	defaultAllPathsMessage = "{{.Caller}} returns without calling callee function"
+	defaultOrderMessage    = "{{.Caller}} calls {{.Guarded}} before {{.Callee}}"
+	defaultPairMessage     = "{{.Caller}} calls {{.Callee}} without calling {{.Release}}"
+	defaultFlowMessage     = "{{.Caller}} calls {{.Callee}} without passing its parameter"
+	defaultFoundMessage    = "{{.Caller}} calls callee function"
+	defaultReflectMessage  = "{{.Caller}} may reach {{.Callee}} via reflection"
+)
+
+// RuleConfig is the serialized form of a [Rule] in a config file.
+type RuleConfig struct {
+	Name           string         `json:"name"`
+	Mode           string         `json:"mode"`
+	Caller         CallerConfig   `json:"caller"`
+	Callee         CalleeConfig   `json:"callee"`
+	Guarded        CalleeConfig   `json:"guarded"`
+	Release        []CalleeConfig `json:"release"`
+	Flow           *Flow          `json:"flow"`
+	Require        string         `json:"require"`
+	SkipFile       []string       `json:"skip_file"`
+	IgnoreGo       bool           `json:"ignore_go"`
+	IgnoreChannels bool           `json:"ignore_channels"`
+	Severity       string         `json:"severity"`
+	Message        string         `json:"message"`
+}
+
+// CallerConfig is the serialized form of [CallerOpts].
+type CallerConfig struct {
+	Names          []string `json:"names"`
+	NamesRegex     string   `json:"names_regex"`
+	NamesGlob      []string `json:"names_glob"`
+	Implements     []string `json:"implements"`
+	OnlyDirectives bool     `json:"only_directives"`
+	Pkg            []string `json:"pkg"`
+	Params         []string `json:"params"`
+	Results        []string `json:"results"`
+}
+
+// CalleeConfig is the serialized form of [CalleeOpts].
+type CalleeConfig struct {
+	Name    string   `json:"name"`
+	Pkg     []string `json:"pkg"`
+	PkgPath []string `json:"pkgpath"`
+	Params  []string `json:"params"`
+	Results []string `json:"results"`
+}
+
+func (c CalleeConfig) opts() CalleeOpts {
+	return CalleeOpts{
+		Name:        c.Name,
+		PkgPrefixes: c.Pkg,
+		PkgPaths:    c.PkgPath,
+		Params:      c.Params,
+		Results:     c.Results,
+	}
+}
+
+// FileConfig is the content of a config file.
+type FileConfig struct {
+	Rules []RuleConfig `json:"rules"`
+}
+
+// Parse converts the config into a [Rule].
+func (c RuleConfig) Parse() (Rule, error) {
+	r := Rule{
+		Name: c.Name,
+		Caller: CallerOpts{
+			NamesRegex:     c.Caller.NamesRegex,
+			NamesGlobs:     c.Caller.NamesGlob,
+			Implements:     c.Caller.Implements,
+			OnlyDirectives: c.Caller.OnlyDirectives,
+			PkgPrefixes:    c.Caller.Pkg,
+			Params:         c.Caller.Params,
+			Results:        c.Caller.Results,
+		},
+		Callee:           c.Callee.opts(),
+		Guarded:          c.Guarded.opts(),
+		Flow:             c.Flow,
+		SkipFileSuffixes: c.SkipFile,
+		IgnoreGo:         c.IgnoreGo,
+		IgnoreChannels:   c.IgnoreChannels,
+		Severity:         c.Severity,
+		Message:          c.Message,
+	}
+	if r.Name == "" {
+		return r, fmt.Errorf("rule has no name")
+	}
+	if err := setMode(&r.Mode)(c.Mode); err != nil {
+		return r, fmt.Errorf("rule %s: %w", r.Name, err)
+	}
+	if err := setRequire(&r.Require)(c.Require); err != nil {
+		return r, fmt.Errorf("rule %s: %w", r.Name, err)
+	}
+	for _, release := range c.Release {
+		r.Release = append(r.Release, release.opts())
+	}
+	if len(c.Caller.Names) > 0 {
+		r.Caller.Names = make(map[string]struct{}, len(c.Caller.Names))
+		for _, n := range c.Caller.Names {
+			r.Caller.Names[n] = struct{}{}
+		}
+	}
+	return r, nil
+}
+
+// Parse converts all rules of the config.
+func (c FileConfig) Parse() ([]Rule, error) {
+	rules := make([]Rule, len(c.Rules))
+	names := make(map[string]struct{}, len(c.Rules))
+	for i, rc := range c.Rules {
+		r, err := rc.Parse()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := names[r.Name]; ok {
+			return nil, fmt.Errorf("duplicate rule %s", r.Name)
+		}
+		names[r.Name] = struct{}{}
+		rules[i] = r
+	}
+	return rules, nil
+}
+
+// LoadRules reads the rules from the JSON config file at path.
+func LoadRules(path string) ([]Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c FileConfig
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	rules, err := c.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+var configCache sync.Map // path -> []Rule
+
+// loadRulesCached is [LoadRules], but only reads each file once.
+// The config file is loaded for every analyzed package.
+func loadRulesCached(path string) ([]Rule, error) {
+	if rules, ok := configCache.Load(path); ok {
+		return rules.([]Rule), nil
+	}
+	rules, err := LoadRules(path)
+	if err != nil {
+		return nil, err
+	}
+	configCache.Store(path, rules)
+	return rules, nil
+}
+
+// rule is a [Rule] ready to be evaluated.
+type rule struct {
+	*Rule
+
+	callers      []funcSelector
+	callersRegex *regexp.Regexp
+	callee       funcSelector
+
+	// Rule matching the guarded function, in ModeOrder.
+	guarded *rule
+
+	// Rules matching the release functions, in ModePair.
+	releases []*rule
+
+	// Rule matching the callee with any call, if the rule requires deferred calls.
+	// Summaries include paths which are not deferred, see [reach.Deferred].
+	undeferred *rule
+
+	// Interfaces of Caller.Implements by package.
+	ifaceCache sync.Map
+
+	msg        *template.Template
+	foundMsg   *template.Template
+	flowMsg    *template.Template
+	reflectMsg *template.Template
+
+	reportPaths bool
+}
+
+func compileRule(r *Rule) (*rule, error) {
+	text := r.Message
+	if text == "" {
+		switch r.Mode {
+		case ModeForbid:
+			text = defaultForbidMessage
+		case ModeAllPaths:
+			text = defaultAllPathsMessage
+		case ModeOrder:
+			text = defaultOrderMessage
+		case ModePair:
+			text = defaultPairMessage
+		default:
+			switch r.Require {
+			case RequireDefer:
+				text = defaultDeferMessage
+			case RequireDirect:
+				text = defaultDirectMessage
+			default:
+				text = defaultRequireMessage
+			}
+		}
+	}
+	msg, err := template.New(r.Name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: message: %w", r.Name, err)
+	}
+	callee, err := parseSelector(r.Callee.Name)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: callee: %w", r.Name, err)
+	}
+	callers := make([]funcSelector, 0, len(r.Caller.Names))
+	for name := range r.Caller.Names {
+		sel, err := parseSelector(name)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: caller: %w", r.Name, err)
+		}
+		callers = append(callers, sel)
+	}
+	var callersRegex *regexp.Regexp
+	if r.Caller.NamesRegex != "" {
+		callersRegex, err = regexp.Compile(r.Caller.NamesRegex)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: caller: %w", r.Name, err)
+		}
+	}
+	for _, pattern := range r.Caller.NamesGlobs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("rule %s: caller: %q: %w", r.Name, pattern, err)
+		}
+	}
+	for _, iface := range r.Caller.Implements {
+		if _, _, err := parseInterfaceName(iface); err != nil {
+			return nil, fmt.Errorf("rule %s: caller: %w", r.Name, err)
+		}
+	}
+	flowMsg := msg
+	if r.Flow != nil {
+		if r.Mode != "" && r.Mode != ModeRequire {
+			return nil, fmt.Errorf("rule %s: flow requires mode %s", r.Name, ModeRequire)
+		}
+		if r.Flow.Param < 0 || r.Flow.Arg < 0 {
+			return nil, fmt.Errorf("rule %s: flow: negative index", r.Name)
+		}
+		if r.Message == "" {
+			flowMsg = template.Must(template.New(r.Name).Parse(defaultFlowMessage))
+		}
+	}
+	if err := setRequire(new(Require))(string(r.Require)); err != nil {
+		return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+	}
+	if r.Require != "" && r.Require != RequireAny {
+		if r.Mode != "" && r.Mode != ModeRequire && r.Mode != ModeForbid {
+			return nil, fmt.Errorf("rule %s: require %s requires mode %s or %s", r.Name, r.Require, ModeRequire, ModeForbid)
+		}
+		if r.Flow != nil {
+			return nil, fmt.Errorf("rule %s: require %s does not support flow", r.Name, r.Require)
+		}
+	}
+	var undeferred *rule
+	if r.Require == RequireDefer {
+		undeferred, err = compileTarget(r.Name, r.Callee, r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: callee: %w", r.Name, err)
+		}
+	}
+	var guarded *rule
+	if r.Mode == ModeOrder {
+		if r.Guarded.Name == "" {
+			return nil, fmt.Errorf("rule %s: mode %s requires a guarded function", r.Name, r.Mode)
+		}
+		guarded, err = compileTarget(r.Name+".guarded", r.Guarded, r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: guarded: %w", r.Name, err)
+		}
+	}
+	var releases []*rule
+	if r.Mode == ModePair {
+		if len(r.Release) == 0 {
+			return nil, fmt.Errorf("rule %s: mode %s requires a release function", r.Name, r.Mode)
+		}
+		for i, opts := range r.Release {
+			release, err := compileTarget(fmt.Sprintf("%s.release%d", r.Name, i), opts, r)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: release: %w", r.Name, err)
+			}
+			releases = append(releases, release)
+		}
+	}
+	return &rule{
+		Rule:         r,
+		callers:      callers,
+		callersRegex: callersRegex,
+		callee:       callee,
+		guarded:      guarded,
+		releases:     releases,
+		undeferred:   undeferred,
+		msg:          msg,
+		foundMsg:     template.Must(template.New(r.Name).Parse(defaultFoundMessage)),
+		flowMsg:      flowMsg,
+		reflectMsg:   template.Must(template.New(r.Name).Parse(defaultReflectMessage)),
+	}, nil
+}
+
+// compileTarget compiles a rule only matching the callee,
+// used to search for additional functions of a rule.
+// IgnoreGo and IgnoreChannels are inherited from the rule parent.
+func compileTarget(name string, callee CalleeOpts, parent *Rule) (*rule, error) {
+	sel, err := parseSelector(callee.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &rule{
+		Rule: &Rule{
+			Name:           name,
+			Callee:         callee,
+			IgnoreGo:       parent.IgnoreGo,
+			IgnoreChannels: parent.IgnoreChannels,
+		},
+		callee: sel,
+	}, nil
+}
+
+// targets returns the rules whose callees are summarized for r.
+func (r *rule) targets() []*rule {
+	targets := []*rule{r}
+	if r.guarded != nil {
+		targets = append(targets, r.guarded)
+	}
+	return append(targets, r.releases...)
+}
+
+// releaseNames returns the names of the release functions separated by |.
+func (r *rule) releaseNames() string {
+	names := make([]string, len(r.Release))
+	for i, opts := range r.Release {
+		names[i] = opts.Name
+	}
+	return strings.Join(names, "|")
+}
+
+// message formats the diagnostic message, the rule name is set by message.
+func (r *rule) message(tmpl *template.Template, data MessageData) string {
+	var sb strings.Builder
+	if r.Severity != "" {
+		sb.WriteString(r.Severity)
+		sb.WriteString(": ")
+	}
+	data.Rule = r.Name
+	err := tmpl.Execute(&sb, data)
+	if err != nil {
+		return fmt.Sprintf("%s: %s", r.Name, err)
+	}
+	return sb.String()
+}
+
+// flagRuleName is the name of the rule configured by flags.
+const flagRuleName = "sadboy"
+
+// FlagConfig returns the configuration of [Analyzer] set by flags and the config file.
+// The rule configured by flags is used if there is no config file
+// or if it specifies a callee.
+// Returns an error if the configuration is invalid,
+// so it can be validated once before analyzing any package.
+// The configuration is compiled once, the flags must be parsed before the first call.
+func FlagConfig() (Config, error) {
+	cfg, _, err := flagConfig()
+	return cfg, err
+}
+
+// flagRules returns the rules of [FlagConfig].
+func flagRules() ([]*rule, error) {
+	_, rules, err := flagConfig()
+	return rules, err
+}
+
+// flagMemo is the compiled configuration of the flags.
+// The flags are parsed before the first call of [flagConfig].
+var flagMemo struct {
+	mu    sync.Mutex
+	valid bool
+	cfg   Config
+	rules []*rule
+	err   error
+}
+
+func flagConfig() (Config, []*rule, error) {
+	flagMemo.mu.Lock()
+	defer flagMemo.mu.Unlock()
+	if !flagMemo.valid {
+		flagMemo.cfg, flagMemo.rules, flagMemo.err = compileFlagConfig()
+		flagMemo.valid = true
+	}
+	return flagMemo.cfg, flagMemo.rules, flagMemo.err
+}
+
+// resetFlagConfig discards the compiled configuration of the flags,
+// after they were changed by [SetOpts].
+func resetFlagConfig() {
+	flagMemo.mu.Lock()
+	defer flagMemo.mu.Unlock()
+	flagMemo.valid = false
+}
+
+func compileFlagConfig() (Config, []*rule, error) {
+	cfg := Config{
+		ReportPaths:      opts.ReportPaths,
+		Baseline:         opts.BaselineFile,
+		UpdateBaseline:   opts.UpdateBaseline,
+		BaselinePackages: opts.BaselinePackages,
+		CallGraph:        opts.CallGraph,
+		Reflection:       opts.Reflection,
+	}
+	if opts.ConfigFile != "" {
+		fileRules, err := loadRulesCached(opts.ConfigFile)
+		if err != nil {
+			return cfg, nil, err
+		}
+		cfg.Rules = slices.Clone(fileRules)
+	}
+	if opts.ConfigFile == "" || calleeOpts.Name != "" {
+		r := Rule{
+			Name:             flagRuleName,
+			Mode:             opts.Mode,
+			Caller:           callerOpts,
+			Callee:           calleeOpts,
+			Guarded:          CalleeOpts{Name: opts.Guarded},
+			SkipFileSuffixes: opts.SkipFileSuffixes,
+			Require:          opts.Require,
+			IgnoreGo:         opts.IgnoreGo,
+			IgnoreChannels:   opts.IgnoreChannels,
+		}
+		for _, name := range opts.Release {
+			r.Release = append(r.Release, CalleeOpts{Name: name})
+		}
+		cfg.Rules = append(cfg.Rules, r)
+	}
+	rules, err := cfg.compile()
+	return cfg, rules, err
+}
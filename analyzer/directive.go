@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+// directivePrefix returns the start of a directive comment of the analyzer name.
+// Directives are scoped by analyzer, analyzers created by [New] only see their own,
+// so independently configured analyzers can run side by side.
+//
+// Supported directives in the doc comment of a function are:
+//
+//	//sadboy:rule <name>        the function is a caller of the rule
+//	//sadboy:require <callee>  the function is a caller of all rules with the callee
+//
+// Trailing comments starting with // are ignored.
+func directivePrefix(name string) string {
+	return "//" + name + ":"
+}
+
+// directive is a parsed directive comment.
+type directive struct {
+	pos  token.Pos
+	verb string
+	args []string
+
+	// Directive as written without arguments, used in messages.
+	name string
+}
+
+// parseDirectives returns all directives of the analyzer name in the comment group.
+func parseDirectives(doc *ast.CommentGroup, name string) []directive {
+	if doc == nil {
+		return nil
+	}
+	var dirs []directive
+	for _, c := range doc.List {
+		text, ok := strings.CutPrefix(c.Text, directivePrefix(name))
+		if !ok {
+			continue
+		}
+		text, _, _ = strings.Cut(text, "//")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			fields = []string{""}
+		}
+		dirs = append(dirs, directive{
+			pos:  c.Pos(),
+			verb: fields[0],
+			args: fields[1:],
+			name: name + ":" + fields[0],
+		})
+	}
+	return dirs
+}
+
+// requires returns true if the callee of the rule is the function selected by sel.
+// The name and the qualified name of the callee, its package prefixes and paths must accept sel.
+// A qualified sel requires the rule to restrict the package of the callee,
+// a rule for any Log is not a rule for audit.Log.
+func (r *rule) requires(sel funcSelector) bool {
+	if sel.name != r.callee.name {
+		return false
+	}
+	if sel.qual == "" {
+		return true
+	}
+	if r.callee.qual == "" && r.Callee.PkgPaths == nil && r.Callee.PkgPrefixes == nil {
+		return false
+	}
+	if r.callee.qual != "" && !sameQual(r.callee, sel) {
+		return false
+	}
+
+	// The package of a short selector is only known by its last element.
+	// Longer selectors may be in one of two packages, see [funcSelector.pkgPaths].
+	paths, short := sel.pkgPaths(), sel.isShort()
+	if r.Callee.PkgPaths != nil && !slices.ContainsFunc(r.Callee.PkgPaths, func(p string) bool {
+		return slices.ContainsFunc(paths, func(path string) bool {
+			return p == path || (short && strings.HasSuffix(p, "/"+path))
+		})
+	}) {
+		return false
+	}
+	if r.Callee.PkgPrefixes != nil && !short && !slices.ContainsFunc(r.Callee.PkgPrefixes, func(p string) bool {
+		return slices.ContainsFunc(paths, func(path string) bool {
+			return strings.HasPrefix(path, p)
+		})
+	}) {
+		return false
+	}
+	return true
+}
+
+// sameQual returns true if the qualified selectors a and b may select the same function,
+// their qualified names are equal or one is the short form of the other.
+func sameQual(a, b funcSelector) bool {
+	return a.qual == b.qual ||
+		(b.isShort() && strings.HasSuffix(a.qual, "/"+b.qual)) ||
+		(a.isShort() && strings.HasSuffix(b.qual, "/"+a.qual))
+}
@@ -0,0 +1,532 @@
+package analyzer
+
+import (
+	"go/types"
+	"maps"
+	"slices"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// summaryFact summarizes the functions of a package,
+// so importing packages don't need to build its SSA.
+//
+// The fact only contains plain data, so it can be serialized
+// by drivers running each package in its own process (go vet -vettool).
+type summaryFact struct {
+	// Summaries by function key, see [funcKey].
+	Funcs map[string]*funcSummary
+}
+
+func (*summaryFact) AFact() {}
+
+func (*summaryFact) String() string {
+	return "summary"
+}
+
+type funcSummary struct {
+	// Rules whose callee is reachable from the function, by rule name.
+	Reaches map[string]reach
+
+	// Indices of parameters holding functions that are called by the function.
+	// For methods, the receiver is the first parameter.
+	CallsParams []int
+
+	// Names of the methods invoked on parameters holding interfaces, by parameter index.
+	// For methods, the receiver is the first parameter.
+	InvokesParams map[int][]string
+
+	// Indices of parameters flowing into the callee argument of a rule with a [Flow], by rule name.
+	Flows map[string][]int
+
+	// Rules whose callee may be reachable through reflection from the function, by rule name,
+	// see [ReflectionConservative]. Only for rules without Reaches.
+	MayReach map[string]reach
+}
+
+// reach is a witness that a function reaches the callee of a rule.
+type reach struct {
+	// Name of the callee function.
+	Callee string
+
+	// The call path after the function, see [pathString].
+	Trace string
+
+	// The call path is deferred, only set for rules with [RequireDefer].
+	// Summaries of such rules include paths which are not deferred,
+	// which reach the callee if the function itself is deferred.
+	Deferred bool
+}
+
+// funcKey returns the identity of fn shared between all packages.
+// Returns "" if fn can not be referenced from other packages.
+func funcKey(fn *ssa.Function) string {
+	if origin := fn.Origin(); origin != nil {
+		fn = origin
+	}
+	obj, ok := fn.Object().(*types.Func)
+	if !ok {
+		return ""
+	}
+	return obj.FullName()
+}
+
+// isExternal returns true if fn has no body in the current program,
+// which means its summary has to be imported.
+func isExternal(fn *ssa.Function) bool {
+	if origin := fn.Origin(); origin != nil {
+		fn = origin
+	}
+	return fn.Blocks == nil
+}
+
+// summaries looks up the summaries of external functions.
+// It is safe for concurrent use.
+type summaries struct {
+	pass *analysis.Pass
+
+	mu    sync.Mutex
+	facts map[*types.Package]*summaryFact
+}
+
+func newSummaries(pass *analysis.Pass) *summaries {
+	return &summaries{
+		pass:  pass,
+		facts: make(map[*types.Package]*summaryFact),
+	}
+}
+
+// lookup returns the summary of fn if fn is external.
+func (s *summaries) lookup(fn *ssa.Function) *funcSummary {
+	if s == nil || !isExternal(fn) {
+		return nil
+	}
+	if origin := fn.Origin(); origin != nil {
+		fn = origin
+	}
+	if fn.Pkg == nil {
+		return nil
+	}
+	key := funcKey(fn)
+	if key == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fact, ok := s.facts[fn.Pkg.Pkg]
+	if !ok {
+		fact = new(summaryFact)
+		if !s.pass.ImportPackageFact(fn.Pkg.Pkg, fact) {
+			fact = nil
+		}
+		s.facts[fn.Pkg.Pkg] = fact
+	}
+	if fact == nil {
+		return nil
+	}
+	return fact.Funcs[key]
+}
+
+// searcher searches paths in the call graph of a package.
+// Calls to external functions are resolved using their summaries.
+// It is safe for concurrent use.
+type searcher struct {
+	pass *analysis.Pass
+	cg   *callgraph.Graph
+	sums *summaries
+	memo *memo
+
+	// Treatment of calls through reflection, which can't be resolved.
+	reflection Reflection
+
+	// mu guards the nodes of cg, synthetic and unresolved.
+	mu sync.Mutex
+
+	// Edges created from summaries and reflection, they have no matching call site.
+	synthetic map[*callgraph.Edge]struct{}
+
+	// Nodes standing for calls through reflection, which can't be resolved, by reflect function.
+	unresolved map[*ssa.Function]*callgraph.Node
+}
+
+// node returns the call graph node of fn, creating it if necessary.
+func (s *searcher) node(fn *ssa.Function) *callgraph.Node {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cg.CreateNode(fn)
+}
+
+// isSynthetic returns true if e was created from a summary.
+func (s *searcher) isSynthetic(e *callgraph.Edge) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.synthetic[e]
+	return ok
+}
+
+// isEnd returns a function checking if a node reaches the callee of r,
+// either by being the callee or by a summary.
+func (s *searcher) isEnd(r *rule) func(n *callgraph.Node) bool {
+	return func(n *callgraph.Node) bool {
+		if r.isCallee(n.Func) {
+			return true
+		}
+		if sum := s.sums.lookup(n.Func); sum != nil {
+			_, ok := sum.Reaches[r.Name]
+			return ok
+		}
+		return false
+	}
+}
+
+// search finds a path from start to the callee of r.
+// Results are memoized, the returned path must not be modified.
+func (s *searcher) search(start *callgraph.Node, r *rule) []*callgraph.Edge {
+	return s.searchMemo(memoKey{start, r, false}, s.isEnd(r))
+}
+
+// searchReflection finds a path from start to the callee of r,
+// or to a call through reflection, which can't be resolved,
+// if reflection is treated conservatively.
+func (s *searcher) searchReflection(start *callgraph.Node, r *rule) []*callgraph.Edge {
+	if s.reflection != ReflectionConservative {
+		return nil
+	}
+	isEnd := s.isEnd(r)
+	path := s.searchMemo(memoKey{start, r, true}, func(n *callgraph.Node) bool {
+		if isEnd(n) || s.isUnresolved(n) {
+			return true
+		}
+		if sum := s.sums.lookup(n.Func); sum != nil {
+			_, ok := sum.MayReach[r.Name]
+			return ok
+		}
+		return false
+	})
+	// The path ends at the reflective call, not at the node standing for it.
+	if len(path) > 1 && s.isUnresolved(path[len(path)-1].Callee) {
+		path = path[:len(path)-1]
+	}
+	return path
+}
+
+func (s *searcher) searchMemo(key memoKey, isEnd func(*callgraph.Node) bool) []*callgraph.Edge {
+	if path, ok := s.memo.path(key); ok {
+		return path
+	}
+	reaching := s.reaching(key.rule)
+	path := s.pathSearch(key.start, func(n *callgraph.Node, stack []*callgraph.Edge) bool {
+		return isEnd(n) && s.deferredEnough(key.rule, n, stack)
+	}, func(n *callgraph.Node) bool {
+		return !reaching[n]
+	}, key.rule.skips, func(n *callgraph.Node, stack []*callgraph.Edge) []*callgraph.Edge {
+		return s.expand(n, stack, key.rule)
+	})
+	s.memo.setPath(key, path)
+	return path
+}
+
+// reach returns the callee and trace of the rule at the end of the path.
+// Functions in the trace are named relative to package from.
+func (s *searcher) reach(caller *ssa.Function, path []*callgraph.Edge, r *rule, from *types.Package) reach {
+	end := pathEnd(caller, path)
+	res := reach{
+		Callee: end.Name(),
+		Trace:  pathString(s.pass.Fset, from, path),
+	}
+	if !r.isCallee(end) {
+		if sum := s.sums.lookup(end); sum != nil {
+			ext, ok := sum.Reaches[r.Name]
+			if !ok {
+				ext = sum.MayReach[r.Name]
+			}
+			res.Callee = ext.Callee
+			res.Trace += ext.Trace
+		}
+	}
+	return res
+}
+
+// expand returns the edges of n missing in the call graph:
+// edges from an external function to the functions passed as arguments,
+// which the function calls according to its summary,
+// edges of calls through reflection and type assertions, see [Reflection],
+// edges from an external function to the methods of the concrete values passed as arguments,
+// which the function invokes on its interface parameters according to its summary,
+// and edges of functions sent over channels, unless r ignores them, see [searcher.sendEdges].
+// stack is the path up to n.
+func (s *searcher) expand(n *callgraph.Node, stack []*callgraph.Edge, r *rule) []*callgraph.Edge {
+	out := s.assertEdges(n, stack)
+	if !r.IgnoreChannels {
+		out = append(out, s.sendEdges(n, stack)...)
+	}
+	if len(stack) == 0 {
+		return out
+	}
+	inc := stack[len(stack)-1]
+	if isReflectCall(n.Func) {
+		out = append(out, s.reflectEdges(inc, stack[:len(stack)-1])...)
+	}
+	sum := s.sums.lookup(inc.Callee.Func)
+	if sum == nil {
+		return out
+	}
+
+	add := func(fn *ssa.Function) {
+		s.mu.Lock()
+		e := &callgraph.Edge{
+			Caller: inc.Callee,
+			Site:   inc.Site,
+			Callee: s.cg.CreateNode(fn),
+		}
+		s.synthetic[e] = struct{}{}
+		s.mu.Unlock()
+		out = append(out, e)
+	}
+	for _, i := range sum.CallsParams {
+		fns, _ := s.funcsOf(argOf(inc, i), stack[:len(stack)-1])
+		for _, fn := range fns {
+			add(fn)
+		}
+	}
+	for _, i := range slices.Sorted(maps.Keys(sum.InvokesParams)) {
+		srcs, _ := s.sources(argOf(inc, i), stack[:len(stack)-1])
+		for _, name := range sum.InvokesParams[i] {
+			for _, fn := range methodsByName(inc.Site.Parent().Prog, srcs, name) {
+				add(fn.(*ssa.Function))
+			}
+		}
+	}
+	return out
+}
+
+// funcsOf returns the functions v may hold.
+// Parameters are resolved using the call path leading to their function.
+// ok is false if v may hold other functions, see [searcher.sources].
+func (s *searcher) funcsOf(v ssa.Value, stack []*callgraph.Edge) (fns []*ssa.Function, ok bool) {
+	srcs, ok := s.sources(v, stack)
+	for _, src := range srcs {
+		switch src := src.(type) {
+		case *ssa.Function:
+			fns = append(fns, src)
+		case *ssa.MakeClosure:
+			fns = append(fns, src.Fn.(*ssa.Function))
+		}
+	}
+	return fns, ok
+}
+
+// argOf returns the value passed as i-th parameter of the callee of e.
+func argOf(e *callgraph.Edge, i int) ssa.Value {
+	common := e.Site.Common()
+	if common.IsInvoke() {
+		if i == 0 {
+			return common.Value
+		}
+		i--
+	}
+	if i < 0 || i >= len(common.Args) {
+		return nil
+	}
+	return common.Args[i]
+}
+
+// summarize creates the summaries of all functions of the package that can be
+// referenced by other packages.
+func (s *searcher) summarize(fns []*ssa.Function, rules []*rule) *summaryFact {
+	called, invoked := calledParams(fns, s.sums)
+
+	// Summarize in parallel, and collect the summaries in order.
+	sums := make([]*funcSummary, len(fns))
+	parallel(len(fns), func(i int) {
+		fn := fns[i]
+		if funcKey(fn) == "" || fn.Origin() != nil || isSynthetic(fn) {
+			return
+		}
+
+		sum := &funcSummary{}
+		for _, rr := range rules {
+			for _, r := range rr.targets() {
+				path := s.search(s.node(fn), r)
+				deferred := path != nil && r.Require == RequireDefer
+				if path == nil && r.undeferred != nil {
+					path = s.search(s.node(fn), r.undeferred)
+				}
+				if path == nil {
+					if path := s.searchReflection(s.node(fn), r); path != nil {
+						if sum.MayReach == nil {
+							sum.MayReach = make(map[string]reach)
+						}
+						sum.MayReach[r.Name] = s.reach(fn, path, r, nil)
+					}
+					continue
+				}
+				if sum.Reaches == nil {
+					sum.Reaches = make(map[string]reach)
+				}
+				reach := s.reach(fn, path, r, nil)
+				reach.Deferred = deferred
+				sum.Reaches[r.Name] = reach
+
+				if r.Flow == nil {
+					continue
+				}
+				if params := s.flowParams(fn, r); params != nil {
+					if sum.Flows == nil {
+						sum.Flows = make(map[string][]int)
+					}
+					sum.Flows[r.Name] = params
+				}
+			}
+		}
+		for i, p := range fn.Params {
+			if _, ok := called[p]; ok {
+				sum.CallsParams = append(sum.CallsParams, i)
+			}
+			if names := invoked[p]; names != nil {
+				if sum.InvokesParams == nil {
+					sum.InvokesParams = make(map[int][]string)
+				}
+				sum.InvokesParams[i] = slices.Sorted(slices.Values(names))
+			}
+		}
+
+		if sum.Reaches != nil || sum.CallsParams != nil || sum.InvokesParams != nil || sum.MayReach != nil {
+			sums[i] = sum
+		}
+	})
+
+	fact := &summaryFact{
+		Funcs: make(map[string]*funcSummary),
+	}
+	for i, sum := range sums {
+		if sum != nil {
+			fact.Funcs[funcKey(fns[i])] = sum
+		}
+	}
+	return fact
+}
+
+// calledParams returns all parameters and free variables of fns
+// that hold functions which are called by their function,
+// either directly or by passing them to another function calling them,
+// and the names of the methods invoked on those holding interfaces.
+func calledParams(fns []*ssa.Function, sums *summaries) (called map[ssa.Value]struct{}, invoked map[ssa.Value][]string) {
+	called = make(map[ssa.Value]struct{})
+	invoked = make(map[ssa.Value][]string)
+
+	// param returns the parameter or free variable v refers to.
+	param := func(v ssa.Value) ssa.Value {
+		for {
+			switch t := v.(type) {
+			case *ssa.ChangeType:
+				v = t.X
+			case *ssa.MakeInterface:
+				v = t.X
+			case *ssa.Parameter, *ssa.FreeVar:
+				return v
+			default:
+				return nil
+			}
+		}
+	}
+
+	// callsParam reports if fn calls its i-th parameter.
+	callsParam := func(fn *ssa.Function, i int) bool {
+		if sum := sums.lookup(fn); sum != nil {
+			return slices.Contains(sum.CallsParams, i)
+		}
+		// Instances share the parameters of their generic function.
+		if origin := fn.Origin(); origin != nil {
+			fn = origin
+		}
+		if i < 0 || i >= len(fn.Params) {
+			return false
+		}
+		_, ok := called[fn.Params[i]]
+		return ok
+	}
+
+	// invokesParam returns the methods fn invokes on its i-th parameter.
+	invokesParam := func(fn *ssa.Function, i int) []string {
+		if sum := sums.lookup(fn); sum != nil {
+			return sum.InvokesParams[i]
+		}
+		if origin := fn.Origin(); origin != nil {
+			fn = origin
+		}
+		if i < 0 || i >= len(fn.Params) {
+			return nil
+		}
+		return invoked[fn.Params[i]]
+	}
+
+	mark := func(v ssa.Value) bool {
+		if v == nil {
+			return false
+		}
+		if _, ok := called[v]; ok {
+			return false
+		}
+		called[v] = struct{}{}
+		return true
+	}
+
+	markInvoked := func(v ssa.Value, name string) bool {
+		if v == nil || slices.Contains(invoked[v], name) {
+			return false
+		}
+		invoked[v] = append(invoked[v], name)
+		return true
+	}
+
+	// Iterate until no new called parameters are found,
+	// since functions can pass their parameters to each other.
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range fns {
+			for _, b := range fn.Blocks {
+				for _, instr := range b.Instrs {
+					switch instr := instr.(type) {
+					case ssa.CallInstruction:
+						common := instr.Common()
+						if common.IsInvoke() {
+							changed = markInvoked(param(common.Value), common.Method.Name()) || changed
+						} else {
+							changed = mark(param(common.Value)) || changed
+						}
+						callee := common.StaticCallee()
+						if callee == nil {
+							continue
+						}
+						for j, arg := range common.Args {
+							p := param(arg)
+							if p == nil {
+								continue
+							}
+							if callsParam(callee, j) {
+								changed = mark(p) || changed
+							}
+							for _, name := range invokesParam(callee, j) {
+								changed = markInvoked(p, name) || changed
+							}
+						}
+					case *ssa.MakeClosure:
+						fv := instr.Fn.(*ssa.Function).FreeVars
+						for k, b := range instr.Bindings {
+							if _, ok := called[fv[k]]; !ok {
+								continue
+							}
+							changed = mark(param(b)) || changed
+						}
+					}
+				}
+			}
+		}
+	}
+	return called, invoked
+}
@@ -0,0 +1,40 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+// Drivers running each package in its own process (go vet -vettool)
+// gob encode facts to pass them to importing packages.
+func TestSummaryFactGob(t *testing.T) {
+	fact := &summaryFact{
+		Funcs: map[string]*funcSummary{
+			"callers/other.CallCallee": {
+				Reaches: map[string]reach{
+					"sadboy": {Callee: "Callee", Trace: " -> callers/callee.Callee (other.go:6)"},
+				},
+			},
+			"slices.SortFunc": {
+				CallsParams: []int{1},
+			},
+			"program/iface.Use": {
+				InvokesParams: map[int][]string{0: {"Log"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(fact); err != nil {
+		t.Fatal(err)
+	}
+	got := new(summaryFact)
+	if err := gob.NewDecoder(&buf).Decode(got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fact, got) {
+		t.Fatalf("got %+v, want %+v", got, fact)
+	}
+}
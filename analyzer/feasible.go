@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// feasible returns false if the call site of e can't call the callee of e,
+// when reached by the call path stack.
+//
+// The call graph merges all function values and interfaces flowing into a call site,
+// e.g. all closures passed to slices.SortFunc are callees of the comparison inside of it.
+// The called value is traced back through the parameters of the functions on stack
+// (the call string), the site can only call the functions and methods of its sources.
+// Values with an unknown source, like fields or results of calls, call all callees.
+func (s *searcher) feasible(e *callgraph.Edge, stack []*callgraph.Edge) bool {
+	if e.Site == nil || s.isSynthetic(e) {
+		return true
+	}
+	common := e.Site.Common()
+	if common.StaticCallee() != nil {
+		return true
+	}
+	srcs, ok := s.sources(common.Value, stack)
+	if !ok {
+		return true
+	}
+	return slices.ContainsFunc(srcs, func(src ssa.Value) bool {
+		return calls(src, e.Callee.Func, common.IsInvoke())
+	})
+}
+
+// calls returns true if a call of src can call fn.
+// invoke is true for calls of interface methods.
+func calls(src ssa.Value, fn *ssa.Function, invoke bool) bool {
+	switch src := src.(type) {
+	case *ssa.Function:
+		// Function types with methods, e.g. http.HandlerFunc, lost their type.
+		return invoke || src == fn || src == fn.Origin()
+	case *ssa.MakeClosure:
+		return invoke || src.Fn == fn
+	}
+	if recv := fn.Signature.Recv(); invoke && recv != nil {
+		return types.Identical(recv.Type(), src.Type())
+	}
+	return true
+}
+
+// sources returns the values v may originate from.
+//
+// Parameters are followed through the call path stack leading to their function,
+// free variables of closures called directly to their bindings,
+// and interfaces to the concrete values they were made from or asserted to.
+// Values of reflect.ValueOf are followed to its argument,
+// and of reflect.Value.MethodByName to the methods of the value.
+// ok is false if a function value or interface has an unknown source.
+func (s *searcher) sources(v ssa.Value, stack []*callgraph.Edge) (srcs []ssa.Value, ok bool) {
+	type visit struct {
+		v     ssa.Value
+		depth int
+	}
+	ok = true
+	seen := make(map[visit]struct{})
+
+	var walk func(v ssa.Value, stack []*callgraph.Edge)
+	walk = func(v ssa.Value, stack []*callgraph.Edge) {
+		if v == nil {
+			ok = false
+			return
+		}
+		if _, dup := seen[visit{v, len(stack)}]; dup {
+			return
+		}
+		seen[visit{v, len(stack)}] = struct{}{}
+
+		switch v := v.(type) {
+		case *ssa.Function, *ssa.MakeClosure:
+			srcs = append(srcs, v)
+			return
+		case *ssa.MakeInterface:
+			walk(v.X, stack)
+			return
+		case *ssa.ChangeType:
+			walk(v.X, stack)
+			return
+		case *ssa.ChangeInterface:
+			walk(v.X, stack)
+			return
+		case *ssa.TypeAssert:
+			walk(v.X, stack)
+			return
+		case *ssa.Extract:
+			if ta, ok := v.Tuple.(*ssa.TypeAssert); ok && v.Index == 0 {
+				walk(ta.X, stack)
+				return
+			}
+		case *ssa.Phi:
+			for _, e := range v.Edges {
+				walk(e, stack)
+			}
+			return
+		case *ssa.Call:
+			callee := v.Call.StaticCallee()
+			switch {
+			case isReflect(callee, "ValueOf") && callee.Signature.Recv() == nil:
+				walk(v.Call.Args[0], stack)
+				return
+			case isReflect(callee, "MethodByName") && callee.Signature.Recv() != nil:
+				if name, isConst := constString(v.Call.Args[1]); isConst {
+					recv, recvOK := s.sources(v.Call.Args[0], stack)
+					ok = ok && recvOK
+					srcs = append(srcs, methodsByName(v.Parent().Prog, recv, name)...)
+					return
+				}
+			}
+		case *ssa.Parameter:
+			if inc, rest := incoming(v.Parent(), stack); inc != nil && !s.isSynthetic(inc) {
+				walk(argOf(inc, slices.Index(v.Parent().Params, v)), rest)
+				return
+			}
+		case *ssa.FreeVar:
+			if inc, rest := incoming(v.Parent(), stack); inc != nil && !s.isSynthetic(inc) {
+				if mc, ok := inc.Site.Common().Value.(*ssa.MakeClosure); ok && mc.Fn == v.Parent() {
+					walk(mc.Bindings[slices.Index(v.Parent().FreeVars, v)], rest)
+					return
+				}
+			}
+		}
+		if isDynamic(v.Type()) {
+			ok = false
+			return
+		}
+		srcs = append(srcs, v)
+	}
+	walk(v, stack)
+	return srcs, ok
+}
+
+// incoming returns the last edge of stack calling fn, and the path leading to it.
+func incoming(fn *ssa.Function, stack []*callgraph.Edge) (*callgraph.Edge, []*callgraph.Edge) {
+	for j := len(stack) - 1; j >= 0; j-- {
+		if stack[j].Callee.Func == fn {
+			return stack[j], stack[:j]
+		}
+	}
+	return nil, nil
+}
+
+// isDynamic returns true if values of type t are called dynamically,
+// which are function values and interfaces.
+func isDynamic(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Signature)
+	return ok || types.IsInterface(t)
+}
+
+// context returns the sources of the function values, interfaces and reflect.Values
+// held by the parameters and free variables of fn, when reached by the call path stack.
+// Feasible paths below fn only depend on the context, so fn is searched once per context.
+func (s *searcher) context(fn *ssa.Function, stack []*callgraph.Edge) string {
+	if fn == nil {
+		return ""
+	}
+	vars := make([]ssa.Value, 0, len(fn.Params)+len(fn.FreeVars))
+	for _, p := range fn.Params {
+		vars = append(vars, p)
+	}
+	for _, fv := range fn.FreeVars {
+		vars = append(vars, fv)
+	}
+
+	var b strings.Builder
+	for i, v := range vars {
+		if !isDynamic(v.Type()) && !isReflectValue(v.Type()) {
+			continue
+		}
+		srcs, ok := s.sources(v, stack)
+		fmt.Fprintf(&b, "%d:", i)
+		for _, src := range srcs {
+			fmt.Fprintf(&b, "%p,", src)
+		}
+		if !ok {
+			b.WriteString("?")
+		}
+		b.WriteString(";")
+	}
+	return b.String()
+}
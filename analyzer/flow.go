@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Flow requires a parameter of the caller to be passed to the callee.
+//
+// Values derived from the parameter flow as well,
+// including results of calls taking the parameter like context.WithTimeout(ctx, d).
+type Flow struct {
+	// Index of the caller parameter, the receiver of a method is parameter 0.
+	Param int `json:"param"`
+
+	// Index of the callee argument, the receiver of a method is argument 0.
+	Arg int `json:"arg"`
+}
+
+// flowSearch finds a path from fn to the callee of r,
+// on which the parameters of fn at params flow into the argument r.Flow.Arg of the callee.
+func (s *searcher) flowSearch(fn *ssa.Function, params []int, r *rule) []*callgraph.Edge {
+	seen := make(map[string]struct{})
+	reaching := s.reaching(r)
+
+	var search func(fn *ssa.Function, vars []int) []*callgraph.Edge
+	search = func(fn *ssa.Function, vars []int) []*callgraph.Edge {
+		key := fmt.Sprint(fn, vars)
+		if _, ok := seen[key]; ok {
+			return nil
+		}
+		seen[key] = struct{}{}
+
+		flows := flowingValues(fn, vars)
+		for _, e := range s.node(fn).Out {
+			if s.isSynthetic(e) || r.skips(e) {
+				continue
+			}
+			callee := e.Callee.Func
+			if r.isCallee(callee) {
+				if flows[argOf(e, r.Flow.Arg)] {
+					return []*callgraph.Edge{e}
+				}
+				continue
+			}
+			if sum := s.sums.lookup(callee); sum != nil {
+				if slices.ContainsFunc(sum.Flows[r.Name], func(i int) bool { return flows[argOf(e, i)] }) {
+					return []*callgraph.Edge{e}
+				}
+				continue
+			}
+			if !reaching[e.Callee] {
+				continue
+			}
+
+			next := calleeVars(e, flows)
+			if len(next) == 0 {
+				continue
+			}
+			if path := search(callee, next); path != nil {
+				return append([]*callgraph.Edge{e}, path...)
+			}
+		}
+		return nil
+	}
+	return search(fn, params)
+}
+
+// calleeVars returns the indices of the parameters and free variables of the callee of e,
+// which hold flowing values. Free variables follow the parameters.
+func calleeVars(e *callgraph.Edge, flows map[ssa.Value]bool) []int {
+	callee := e.Callee.Func
+	var vars []int
+	for i := range callee.Params {
+		if flows[argOf(e, i)] {
+			vars = append(vars, i)
+		}
+	}
+	// Closures called directly, func() { ... }().
+	if mc, ok := e.Site.Common().Value.(*ssa.MakeClosure); ok && mc.Fn == callee {
+		for k, b := range mc.Bindings {
+			if flows[b] {
+				vars = append(vars, len(callee.Params)+k)
+			}
+		}
+	}
+	return vars
+}
+
+// flowingValues returns all values of fn derived from the parameters and free variables at vars.
+func flowingValues(fn *ssa.Function, vars []int) map[ssa.Value]bool {
+	flows := make(map[ssa.Value]bool)
+	var queue []ssa.Value
+	add := func(v ssa.Value) {
+		if !flows[v] {
+			flows[v] = true
+			queue = append(queue, v)
+		}
+	}
+	for _, i := range vars {
+		if i < len(fn.Params) {
+			add(fn.Params[i])
+		} else if i-len(fn.Params) < len(fn.FreeVars) {
+			add(fn.FreeVars[i-len(fn.Params)])
+		}
+	}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		refs := v.Referrers()
+		if refs == nil {
+			continue
+		}
+		for _, ref := range *refs {
+			switch ref := ref.(type) {
+			case *ssa.ChangeType, *ssa.ChangeInterface, *ssa.MakeInterface, *ssa.Convert,
+				*ssa.TypeAssert, *ssa.Phi, *ssa.Extract, *ssa.Call:
+				add(ref.(ssa.Value))
+			case *ssa.UnOp:
+				add(ref)
+			case *ssa.Store:
+				// Variables captured by closures are stored in allocs.
+				if ref.Val == v {
+					if alloc, ok := ref.Addr.(*ssa.Alloc); ok {
+						add(alloc)
+					}
+				}
+			}
+		}
+	}
+	return flows
+}
+
+// flowParams returns the indices of the parameters of fn flowing into the callee of r.
+func (s *searcher) flowParams(fn *ssa.Function, r *rule) []int {
+	var params []int
+	for i := range fn.Params {
+		if s.flowSearch(fn, []int{i}, r) != nil {
+			params = append(params, i)
+		}
+	}
+	return params
+}
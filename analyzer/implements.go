@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// parseInterfaceName splits a qualified interface name (path/to/pkg.Name)
+// into package path and name.
+func parseInterfaceName(s string) (path, name string, err error) {
+	dot := strings.LastIndexByte(s, '.')
+	if dot <= 0 || dot == len(s)-1 || strings.LastIndexByte(s, '/') > dot {
+		return "", "", fmt.Errorf("invalid interface %q", s)
+	}
+	return s[:dot], s[dot+1:], nil
+}
+
+// lookupInterface finds the interface path.name in pkg or its (transitive) imports.
+// Returns nil if pkg does not depend on the package of the interface.
+func lookupInterface(pkg *types.Package, path, name string) *types.Interface {
+	seen := make(map[*types.Package]struct{})
+	queue := []*types.Package{pkg}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+
+		if p.Path() == path {
+			obj, ok := p.Scope().Lookup(name).(*types.TypeName)
+			if !ok {
+				return nil
+			}
+			iface, _ := obj.Type().Underlying().(*types.Interface)
+			return iface
+		}
+		queue = append(queue, p.Imports()...)
+	}
+	return nil
+}
+
+// interfaces returns the interfaces of [CallerOpts.Implements] as seen from pkg.
+func (r *rule) interfaces(pkg *types.Package) []*types.Interface {
+	if ifaces, ok := r.ifaceCache.Load(pkg); ok {
+		return ifaces.([]*types.Interface)
+	}
+	var ifaces []*types.Interface
+	for _, qual := range r.Caller.Implements {
+		path, name, _ := parseInterfaceName(qual)
+		if iface := lookupInterface(pkg, path, name); iface != nil {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	r.ifaceCache.Store(pkg, ifaces)
+	return ifaces
+}
+
+// implements returns true if the method name with signature sig belongs to
+// the method set of an interface of [CallerOpts.Implements]
+// and its receiver implements the interface.
+func (r *rule) implements(pkg *types.Package, name string, sig *types.Signature) bool {
+	recv := sig.Recv()
+	if recv == nil {
+		return false
+	}
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	// The method set of *T contains the methods of T.
+	ptr := types.NewPointer(t)
+
+	for _, iface := range r.interfaces(pkg) {
+		obj, _, _ := types.LookupFieldOrMethod(iface, false, nil, name)
+		if _, ok := obj.(*types.Func); !ok {
+			continue
+		}
+		if types.Implements(ptr, iface) {
+			return true
+		}
+	}
+	return false
+}
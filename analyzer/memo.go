@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"runtime"
+	"sync"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// memo caches the results of searches in one call graph.
+// It is safe for concurrent use.
+//
+// Searches depend on the call path leading to a node (see [searcher.feasible] and [searcher.expand]),
+// so only context free results are cached:
+// the nodes which may reach the callee, ignoring the path leading to them,
+// and the paths of searches starting at a node.
+type memo struct {
+	mu       sync.Mutex
+	reaching map[*rule]func() map[*callgraph.Node]bool
+	paths    map[memoKey][]*callgraph.Edge
+
+	// Call sites calling values received from channels, see [searcher.receivers].
+	receiversOnce sync.Once
+	receivers     []received
+}
+
+type memoKey struct {
+	start *callgraph.Node
+	rule  *rule
+
+	// Search of [searcher.searchReflection].
+	reflection bool
+}
+
+func newMemo() *memo {
+	return &memo{
+		reaching: make(map[*rule]func() map[*callgraph.Node]bool),
+		paths:    make(map[memoKey][]*callgraph.Edge),
+	}
+}
+
+// path returns the cached result of the search key.
+func (m *memo) path(key memoKey) ([]*callgraph.Edge, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path, ok := m.paths[key]
+	return path, ok
+}
+
+// setPath caches the result of the search key.
+// Concurrent searches of the same key find the same path, the first one is kept.
+func (m *memo) setPath(key memoKey, path []*callgraph.Edge) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.paths[key]; !ok {
+		m.paths[key] = path
+	}
+}
+
+// reaching returns the nodes which may reach the callee of r.
+//
+// A node reaches the callee, if there is a path in the call graph to the callee,
+// to a function reaching it according to its summary, or to an external function
+// calling its parameters or invoking their methods, a call through reflection or a type assertion,
+// or a function sending functions over channels,
+// which might be expanded to a function reaching it.
+// The path leading to a node can only prevent it from reaching the callee,
+// so searches skip all other nodes.
+// Nodes created after the first call have no edges and are not included.
+func (s *searcher) reaching(r *rule) map[*callgraph.Node]bool {
+	s.memo.mu.Lock()
+	compute, ok := s.memo.reaching[r]
+	if !ok {
+		compute = sync.OnceValue(func() map[*callgraph.Node]bool {
+			return s.computeReaching(r)
+		})
+		s.memo.reaching[r] = compute
+	}
+	s.memo.mu.Unlock()
+	return compute()
+}
+
+func (s *searcher) computeReaching(r *rule) map[*callgraph.Node]bool {
+	s.mu.Lock()
+	nodes := make([]*callgraph.Node, 0, len(s.cg.Nodes))
+	for _, n := range s.cg.Nodes {
+		nodes = append(nodes, n)
+	}
+	s.mu.Unlock()
+
+	isEnd := s.isEnd(r)
+	reaching := make(map[*callgraph.Node]bool)
+	var queue []*callgraph.Node
+	for _, n := range nodes {
+		// The root of some call graphs has no function.
+		if n.Func == nil {
+			continue
+		}
+		expands := isReflectCall(n.Func) || hasAssertedCalls(n.Func) || (!r.IgnoreChannels && sendsFuncs(n.Func))
+		if sum := s.sums.lookup(n.Func); sum != nil {
+			_, mayReach := sum.MayReach[r.Name]
+			expands = expands || sum.CallsParams != nil || sum.InvokesParams != nil || mayReach
+		}
+		if isEnd(n) || expands {
+			reaching[n] = true
+			queue = append(queue, n)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range n.In {
+			if !reaching[e.Caller] && !r.skips(e) {
+				reaching[e.Caller] = true
+				queue = append(queue, e.Caller)
+			}
+		}
+	}
+	return reaching
+}
+
+// prefetch searches the callers of all rules in parallel,
+// so checking them, which reports in order, finds the paths in the memo.
+func (s *searcher) prefetch(rules []*rule, callers map[*rule][]*ssa.Function) {
+	type job struct {
+		r      *rule
+		caller *ssa.Function
+	}
+	var jobs []job
+	for _, r := range rules {
+		for _, caller := range callers[r] {
+			jobs = append(jobs, job{r, caller})
+		}
+	}
+	parallel(len(jobs), func(i int) {
+		r, node := jobs[i].r, s.node(jobs[i].caller)
+		for _, t := range r.targets() {
+			s.search(node, t)
+			// The other modes search from each call site.
+			if r.Mode == ModeAllPaths || r.Mode == ModeOrder || r.Mode == ModePair {
+				for _, e := range node.Out {
+					s.search(e.Callee, t)
+				}
+			}
+		}
+	})
+}
+
+// parallel calls f for all 0 <= i < n on a pool of GOMAXPROCS workers.
+func parallel(n int, f func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(n, runtime.GOMAXPROCS(0)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				f(i)
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -9,10 +9,12 @@ func SetOpts(options func(o *Opts, caller *CallerOpts, callee *CalleeOpts)) func
 	callerOpts = CallerOpts{}
 	calleeOpts = CalleeOpts{}
 	options(&opts, &callerOpts, &calleeOpts)
+	resetFlagConfig()
 
 	return func() {
 		opts = origOpts
 		callerOpts = origCallerOpts
 		calleeOpts = origCalleeOpts
+		resetFlagConfig()
 	}
 }
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkOrder reports every call site in caller reaching the guarded function,
+// which can be reached from the entry of caller without passing a call
+// that reaches the callee first.
+//
+// A call reaching both the callee and the guarded function counts as calling the callee,
+// the order within the called function is checked if it is a caller itself.
+// Deferred calls of the callee run last, so they never count.
+func (r *rule) checkOrder(s *searcher, rep *reporter, caller *ssa.Function) {
+	if len(caller.Blocks) == 0 {
+		return
+	}
+	node := s.node(caller)
+	reaching := s.reachingCalls(node, r, false)
+	called := calledBlocks(caller, reaching)
+
+	for _, b := range caller.Blocks {
+		ok := calledBefore(caller, b, called)
+		for _, instr := range b.Instrs {
+			call, isCall := instr.(ssa.CallInstruction)
+			if !isCall {
+				continue
+			}
+			if reaching[call] {
+				ok = true
+			}
+			if ok {
+				continue
+			}
+			path := s.searchSite(node, call, r.guarded)
+			if path == nil {
+				continue
+			}
+
+			reach := s.reach(caller, path, r.guarded, s.pass.Pkg)
+			d := pathDiagnostic(s.pass.Pkg, caller, path, reach, r.message(r.msg, MessageData{
+				Caller:  caller.Name(),
+				Callee:  r.callee.name,
+				Guarded: reach.Callee,
+			}))
+			d.Pos = call.Pos()
+			rep.reportFinding(r, caller, d)
+		}
+	}
+}
+
+// searchSite finds a path from node through the call site to the callee of r.
+func (s *searcher) searchSite(node *callgraph.Node, site ssa.CallInstruction, r *rule) []*callgraph.Edge {
+	for _, e := range node.Out {
+		if e.Site != site || r.skips(e) {
+			continue
+		}
+		if path := s.search(e.Callee, r); path != nil {
+			return append([]*callgraph.Edge{e}, path...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkPair reports every call site in caller reaching the callee,
+// which is not followed by a call site reaching one of the release functions
+// on every path to a return.
+//
+// A deferred release anywhere in caller releases all call sites.
+// A call reaching both the callee and a release function is balanced,
+// the called function is checked if it is a caller itself.
+func (r *rule) checkPair(s *searcher, rep *reporter, caller *ssa.Function) {
+	if len(caller.Blocks) == 0 {
+		return
+	}
+	node := s.node(caller)
+
+	released := make(map[ssa.CallInstruction]bool)
+	deferred := false
+	for _, release := range r.releases {
+		for site := range s.sitesReaching(node, release) {
+			released[site] = true
+			if _, ok := site.(*ssa.Defer); ok {
+				deferred = true
+			}
+		}
+	}
+
+	releasedFrom := releasedBlocks(caller, released)
+
+	for _, b := range caller.Blocks {
+		for i, instr := range b.Instrs {
+			call, ok := instr.(ssa.CallInstruction)
+			if !ok || released[call] {
+				continue
+			}
+			path := s.searchSite(node, call, r)
+			if path == nil || deferred || releasedAfter(b, i, released, releasedFrom) {
+				continue
+			}
+
+			reach := s.reach(caller, path, r, s.pass.Pkg)
+			d := pathDiagnostic(s.pass.Pkg, caller, path, reach, r.message(r.msg, MessageData{
+				Caller:  caller.Name(),
+				Callee:  reach.Callee,
+				Release: r.releaseNames(),
+			}))
+			d.Pos = call.Pos()
+			rep.reportFinding(r, caller, d)
+		}
+	}
+}
+
+// sitesReaching returns the call sites of node with a callee reaching the callee of r.
+func (s *searcher) sitesReaching(node *callgraph.Node, r *rule) map[ssa.CallInstruction]struct{} {
+	sites := make(map[ssa.CallInstruction]struct{})
+	for _, e := range node.Out {
+		if _, ok := sites[e.Site]; ok || r.skips(e) {
+			continue
+		}
+		if s.search(e.Callee, r) != nil {
+			sites[e.Site] = struct{}{}
+		}
+	}
+	return sites
+}
+
+// releasedAfter returns true if a released call is made on all paths
+// from the i-th instruction of b to a return, see [releasedBlocks].
+func releasedAfter(b *ssa.BasicBlock, i int, released map[ssa.CallInstruction]bool, releasedFrom []bool) bool {
+	if callsAny(b.Instrs[i+1:], released) {
+		return true
+	}
+	if _, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok {
+		return false
+	}
+	for _, succ := range b.Succs {
+		if !releasedFrom[succ.Index] {
+			return false
+		}
+	}
+	return true
+}
+
+// releasedBlocks returns for every block of fn, if a released call is made
+// on all paths from the start of the block to a return.
+// This is the backward counterpart of [calledBlocks].
+// Paths ending in a panic and the recover block are treated as released.
+func releasedBlocks(fn *ssa.Function, released map[ssa.CallInstruction]bool) []bool {
+	releasedFrom := make([]bool, len(fn.Blocks))
+	for i := range releasedFrom {
+		releasedFrom[i] = true
+	}
+
+	// Start with all blocks released and remove blocks with an unreleased path to a return
+	// until nothing changes.
+	for changed := true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			if !releasedFrom[b.Index] || b == fn.Recover || len(b.Instrs) == 0 || callsAny(b.Instrs, released) {
+				continue
+			}
+			if releasedAfter(b, len(b.Instrs)-1, released, releasedFrom) {
+				continue
+			}
+			releasedFrom[b.Index] = false
+			changed = true
+		}
+	}
+	return releasedFrom
+}
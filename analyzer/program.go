@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// LoadMode is the [packages.LoadMode] required by [CheckProgram].
+const LoadMode = packages.LoadAllSyntax
+
+// ProgramDiagnostic is a diagnostic reported by [CheckProgram].
+type ProgramDiagnostic struct {
+	analysis.Diagnostic
+
+	// Position of Pos.
+	Position token.Position
+}
+
+// CheckProgram checks the rules of cfg on pkgs, analyzed as a single program.
+//
+// Unlike the analyzer, which analyzes each package on its own, all packages
+// including their dependencies share one SSA program and one call graph,
+// so calls between packages are resolved like calls within a package.
+// RTA is rooted at the main, init and test functions of the program.
+//
+// pkgs must be loaded with [LoadMode], only the rules of pkgs are evaluated, not of their dependencies.
+// [Config.UpdateBaseline] writes the findings of pkgs, unless [Config.BaselinePackages] is set.
+// Diagnostics are sorted by position.
+func CheckProgram(cfg Config, pkgs []*packages.Package) ([]ProgramDiagnostic, error) {
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return nil, fmt.Errorf("%d errors loading packages", n)
+	}
+	name := cfg.Name
+	if name == "" {
+		name = "sadboy"
+	}
+	cfg.Rules = slices.Clone(cfg.Rules)
+	if cfg.UpdateBaseline && len(cfg.BaselinePackages) == 0 {
+		// The passes have no module, the main module is unknown.
+		for _, pkg := range pkgs {
+			cfg.BaselinePackages = append(cfg.BaselinePackages, pkg.PkgPath)
+		}
+	}
+	c := newChecker(name, sync.OnceValues(cfg.compile), func() Config {
+		return cfg
+	})
+
+	prog, ssaPkgs := ssautil.AllPackages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+
+	progFns := ssautil.AllFunctions(prog)
+	cg, err := buildCallGraph(cfg.CallGraph, prog, progFns, programRoots(prog))
+	if err != nil {
+		return nil, err
+	}
+
+	// All packages search the same call graph without summaries,
+	// so they share the results.
+	m := newMemo()
+
+	// Functions by package, sorted by position.
+	pkgFns := make(map[*types.Package][]*ssa.Function)
+	for fn := range progFns {
+		if fn != nil && fn.Pkg != nil {
+			pkgFns[fn.Pkg.Pkg] = append(pkgFns[fn.Pkg.Pkg], fn)
+		}
+	}
+
+	var diags []ProgramDiagnostic
+	for i, pkg := range pkgs {
+		if ssaPkgs[i] == nil {
+			continue
+		}
+		pass := &analysis.Pass{
+			Analyzer:   c.analyzer,
+			Fset:       pkg.Fset,
+			Files:      pkg.Syntax,
+			Pkg:        pkg.Types,
+			TypesInfo:  pkg.TypesInfo,
+			TypesSizes: pkg.TypesSizes,
+			ResultOf: map[*analysis.Analyzer]any{
+				inspect.Analyzer: inspector.New(pkg.Syntax),
+			},
+			Report: func(d analysis.Diagnostic) {
+				diags = append(diags, ProgramDiagnostic{
+					Diagnostic: d,
+					Position:   pkg.Fset.Position(d.Pos),
+				})
+			},
+			// All functions of the program have a body, there are no summaries.
+			ImportPackageFact: func(*types.Package, analysis.Fact) bool { return false },
+		}
+
+		preScanRes, err := c.runHasCallers(pass)
+		if err != nil {
+			return nil, err
+		}
+
+		fns := pkgFns[pkg.Types]
+		slices.SortFunc(fns, func(a, b *ssa.Function) int {
+			return cmp.Compare(a.Pos(), b.Pos())
+		})
+		s := &searcher{
+			pass:       pass,
+			cg:         cg,
+			sums:       newSummaries(pass),
+			memo:       m,
+			reflection: cfg.Reflection,
+			synthetic:  make(map[*callgraph.Edge]struct{}),
+		}
+		if err := c.check(s, preScanRes.(*preScanResult), fns); err != nil {
+			return nil, err
+		}
+	}
+
+	// Test variants of a package report the same diagnostics.
+	slices.SortFunc(diags, func(a, b ProgramDiagnostic) int {
+		return cmp.Or(
+			cmp.Compare(a.Position.Filename, b.Position.Filename),
+			cmp.Compare(a.Position.Offset, b.Position.Offset),
+			cmp.Compare(a.Message, b.Message),
+		)
+	})
+	diags = slices.CompactFunc(diags, func(a, b ProgramDiagnostic) bool {
+		return a.Position == b.Position && a.Message == b.Message
+	})
+	return diags, nil
+}
+
+// programRoots returns the entry points of prog:
+// the main and init functions and the Test functions of test packages.
+func programRoots(prog *ssa.Program) []*ssa.Function {
+	var roots []*ssa.Function
+	for _, pkg := range prog.AllPackages() {
+		if init := pkg.Func("init"); init != nil {
+			roots = append(roots, init)
+		}
+		if pkg.Pkg.Name() == "main" {
+			if main := pkg.Func("main"); main != nil {
+				roots = append(roots, main)
+			}
+		}
+		for name, m := range pkg.Members {
+			fn, ok := m.(*ssa.Function)
+			if !ok || !strings.HasPrefix(name, "Test") {
+				continue
+			}
+			if file := prog.Fset.File(fn.Pos()); file != nil && strings.HasSuffix(file.Name(), "_test.go") {
+				roots = append(roots, fn)
+			}
+		}
+	}
+	return roots
+}
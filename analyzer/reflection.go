@@ -0,0 +1,214 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Reflection controls calls through reflection, which can't be resolved.
+//
+// Calls of reflect.Value.Call and CallSlice are resolved to the functions
+// passed to reflect.ValueOf, and to the methods of the values passed to it
+// for reflect.Value.MethodByName with a constant name.
+// The values are traced like the function values of other calls, see [searcher.feasible].
+type Reflection string
+
+const (
+	// ReflectionIgnore ignores calls through reflection, which can't be resolved.
+	// This is the default.
+	ReflectionIgnore Reflection = "ignore"
+
+	// ReflectionConservative assumes calls through reflection,
+	// which can't be resolved, may reach the callee.
+	// Callers without a path to the callee, but to such a call,
+	// are reported as "may reach via reflection".
+	ReflectionConservative Reflection = "conservative"
+)
+
+func setReflection(o *Reflection) func(string) error {
+	return func(s string) error {
+		switch r := Reflection(s); r {
+		case "", ReflectionIgnore, ReflectionConservative:
+			*o = r
+			return nil
+		default:
+			return fmt.Errorf("unknown reflection mode %q", s)
+		}
+	}
+}
+
+// isReflect returns true if fn is the function or method name of package reflect.
+func isReflect(fn *ssa.Function, name string) bool {
+	if fn == nil || fn.Name() != name {
+		return false
+	}
+	obj, ok := fn.Object().(*types.Func)
+	return ok && obj.Pkg() != nil && obj.Pkg().Path() == "reflect"
+}
+
+// isReflectCall returns true if fn is reflect.Value.Call or CallSlice.
+func isReflectCall(fn *ssa.Function) bool {
+	return isReflect(fn, "Call") || isReflect(fn, "CallSlice")
+}
+
+// isReflectValue returns true if t is reflect.Value.
+func isReflectValue(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Value" && obj.Pkg() != nil && obj.Pkg().Path() == "reflect"
+}
+
+// reflectEdges returns edges from reflect.Value.Call, called at the call site of inc,
+// to the functions and methods held by the value.
+// stack is the path leading to inc.
+//
+// If the value can't be resolved and reflection is treated conservatively,
+// an edge to the unresolved node of the function is added, see [searcher.isUnresolved].
+func (s *searcher) reflectEdges(inc *callgraph.Edge, stack []*callgraph.Edge) []*callgraph.Edge {
+	srcs, ok := s.sources(argOf(inc, 0), stack)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []*callgraph.Edge
+	add := func(callee *callgraph.Node) {
+		e := &callgraph.Edge{
+			Caller: inc.Callee,
+			Site:   inc.Site,
+			Callee: callee,
+		}
+		s.synthetic[e] = struct{}{}
+		out = append(out, e)
+	}
+	for _, src := range srcs {
+		switch src := src.(type) {
+		case *ssa.Function:
+			add(s.cg.CreateNode(src))
+		case *ssa.MakeClosure:
+			add(s.cg.CreateNode(src.Fn.(*ssa.Function)))
+		default:
+			ok = false
+		}
+	}
+	if (!ok || len(srcs) == 0) && s.reflection == ReflectionConservative {
+		if s.unresolved == nil {
+			s.unresolved = make(map[*ssa.Function]*callgraph.Node)
+		}
+		n, exists := s.unresolved[inc.Callee.Func]
+		if !exists {
+			// Not part of the call graph, so it has no edges.
+			n = &callgraph.Node{Func: inc.Callee.Func, ID: -1}
+			s.unresolved[inc.Callee.Func] = n
+		}
+		add(n)
+	}
+	return out
+}
+
+// isUnresolved returns true if n stands for calls through reflection, which can't be resolved.
+func (s *searcher) isUnresolved(n *callgraph.Node) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unresolved[n.Func] == n
+}
+
+// methodsByName returns the methods named name of the types of srcs,
+// like reflect.Value.MethodByName.
+func methodsByName(prog *ssa.Program, srcs []ssa.Value, name string) []ssa.Value {
+	var fns []ssa.Value
+	for _, src := range srcs {
+		t := src.Type()
+		if types.IsInterface(t) {
+			continue
+		}
+		sel := prog.MethodSets.MethodSet(t).Lookup(nil, name)
+		if sel == nil {
+			continue
+		}
+		if fn := prog.MethodValue(sel); fn != nil {
+			fns = append(fns, fn)
+		}
+	}
+	return fns
+}
+
+// assertEdges returns edges from the calls of n of function values asserted from interfaces,
+// like f.(func())(), to the functions the interface may hold, which are missing in the call graph.
+// stack is the path leading to n.
+func (s *searcher) assertEdges(n *callgraph.Node, stack []*callgraph.Edge) []*callgraph.Edge {
+	if n.Func == nil {
+		return nil
+	}
+	var out []*callgraph.Edge
+	for _, b := range n.Func.Blocks {
+		for _, instr := range b.Instrs {
+			site, ok := instr.(ssa.CallInstruction)
+			if !ok || !isAsserted(site.Common().Value) {
+				continue
+			}
+			fns, _ := s.funcsOf(site.Common().Value, stack)
+			for _, fn := range fns {
+				if hasEdge(n, site, fn) {
+					continue
+				}
+				// The arguments of the call site are passed to the callee,
+				// so the edge is not synthetic.
+				out = append(out, &callgraph.Edge{
+					Caller: n,
+					Site:   site,
+					Callee: s.node(fn),
+				})
+			}
+		}
+	}
+	return out
+}
+
+// hasAssertedCalls returns true if fn calls a function value asserted from an interface.
+func hasAssertedCalls(fn *ssa.Function) bool {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if site, ok := instr.(ssa.CallInstruction); ok && isAsserted(site.Common().Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isAsserted returns true if v is asserted from an interface, f.(func()) or fn, ok := f.(func()).
+func isAsserted(v ssa.Value) bool {
+	switch v := v.(type) {
+	case *ssa.TypeAssert:
+		return true
+	case *ssa.Extract:
+		_, ok := v.Tuple.(*ssa.TypeAssert)
+		return ok && v.Index == 0
+	}
+	return false
+}
+
+// hasEdge returns true if the call graph has an edge from n at site to fn.
+func hasEdge(n *callgraph.Node, site ssa.CallInstruction, fn *ssa.Function) bool {
+	for _, e := range n.Out {
+		if e.Site == site && e.Callee.Func == fn {
+			return true
+		}
+	}
+	return false
+}
+
+// constString returns the value of v if it is a constant string.
+func constString(v ssa.Value) (string, bool) {
+	c, ok := v.(*ssa.Const)
+	if !ok || c.Value == nil || c.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(c.Value), true
+}
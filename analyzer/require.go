@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Require decides how the callee has to be called, in [ModeRequire] and [ModeForbid].
+//
+// The callee is deferred, if any call on the path from the caller to the callee is deferred,
+// e.g. defer span.End(), or defer cleanup() where cleanup calls span.End.
+type Require string
+
+const (
+	// RequireAny accepts deferred and direct calls of the callee.
+	// This is the default.
+	RequireAny Require = "any"
+
+	// RequireDefer only accepts deferred calls of the callee.
+	RequireDefer Require = "defer"
+
+	// RequireDirect only accepts calls of the callee which are not deferred.
+	RequireDirect Require = "direct"
+)
+
+func setRequire(o *Require) func(string) error {
+	return func(s string) error {
+		switch r := Require(s); r {
+		case "", RequireAny, RequireDefer, RequireDirect:
+			*o = r
+			return nil
+		default:
+			return fmt.Errorf("unknown require %q", s)
+		}
+	}
+}
+
+// skips returns true if searches of r don't follow e:
+// go statements if r ignores them, see [Rule.IgnoreGo],
+// and deferred calls if r requires direct calls.
+func (r *rule) skips(e *callgraph.Edge) bool {
+	switch e.Site.(type) {
+	case *ssa.Go:
+		return r.IgnoreGo
+	case *ssa.Defer:
+		return r.Require == RequireDirect
+	}
+	return false
+}
+
+// isDeferred returns true if a call on the path stack is deferred.
+func isDeferred(stack []*callgraph.Edge) bool {
+	return slices.ContainsFunc(stack, func(e *callgraph.Edge) bool {
+		_, ok := e.Site.(*ssa.Defer)
+		return ok
+	})
+}
+
+// deferredEnough returns true if the path stack ending at n is deferred,
+// if r requires deferred calls.
+// Paths ending at the summary of an external function are deferred,
+// if the path in the summary is deferred.
+func (s *searcher) deferredEnough(r *rule, n *callgraph.Node, stack []*callgraph.Edge) bool {
+	if r.Require != RequireDefer || isDeferred(stack) {
+		return true
+	}
+	if r.isCallee(n.Func) {
+		return false
+	}
+	if sum := s.sums.lookup(n.Func); sum != nil {
+		if ext, ok := sum.Reaches[r.Name]; ok {
+			return ext.Deferred
+		}
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// funcSelector selects functions by name.
+//
+// Supported forms are:
+//
+//	Name
+//	path/to/pkg.Func
+//	path/to/pkg.Type.Method
+//	(path/to/pkg.Type).Method
+//	(*path/to/pkg.Type).Method
+//
+// The package path of a qualified selector matches the full import path.
+// A package path of a single element, pkg.Func, also matches the last element
+// of the import path, so it selects path/to/pkg.Func.
+// Longer paths never match lookalike packages like vendor/path/to/pkg.
+// The last element of a longer path may contain dots, like gopkg.in/yaml.v3.Node.Decode,
+// such packages have no short form.
+// Type.Method selects methods with value and pointer receivers,
+// (*Type).Method only selects methods with pointer receivers.
+type funcSelector struct {
+	// Qualified name as path/to/pkg.Func or path/to/pkg.Type.Method.
+	// Empty for unqualified selectors.
+	qual string
+
+	// Receiver must be a pointer.
+	ptr bool
+
+	// Function or method name.
+	name string
+}
+
+func parseSelector(s string) (funcSelector, error) {
+	if strings.HasPrefix(s, "(") {
+		recv, name, ok := strings.Cut(s[1:], ").")
+		if !ok || name == "" || strings.Contains(name, ".") {
+			return funcSelector{}, fmt.Errorf("invalid selector %q", s)
+		}
+		sel := funcSelector{name: name}
+		sel.ptr = strings.HasPrefix(recv, "*")
+		recv = strings.TrimPrefix(recv, "*")
+
+		dot := strings.LastIndexByte(recv, '.')
+		if dot <= 0 || dot == len(recv)-1 || strings.HasSuffix(recv[:dot], "/") {
+			return funcSelector{}, fmt.Errorf("invalid receiver in selector %q", s)
+		}
+		sel.qual = recv + "." + name
+		return sel, nil
+	}
+
+	// The selector ends with Func or Type.Method after the last element of the package path.
+	lastSlash := strings.LastIndexByte(s, '/')
+	parts := strings.Split(s[lastSlash+1:], ".")
+	if len(parts) == 1 {
+		if lastSlash >= 0 {
+			return funcSelector{}, fmt.Errorf("invalid selector %q", s)
+		}
+		return funcSelector{name: s}, nil
+	}
+
+	// A package path of a single element contains no dots.
+	if slices.Contains(parts, "") || (lastSlash < 0 && len(parts) > 3) {
+		return funcSelector{}, fmt.Errorf("invalid selector %q", s)
+	}
+	return funcSelector{
+		qual: s,
+		name: parts[len(parts)-1],
+	}, nil
+}
+
+// match returns true if the function name in package pkg with signature sig is selected.
+func (sel funcSelector) match(pkg *types.Package, name string, sig *types.Signature) bool {
+	if name != sel.name {
+		return false
+	}
+	if sel.qual == "" {
+		return true
+	}
+	if pkg == nil {
+		return false
+	}
+
+	qual := pkg.Path() + "."
+	if recv := sig.Recv(); recv != nil {
+		t := recv.Type()
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		} else if sel.ptr {
+			return false
+		}
+		named, ok := t.(*types.Named)
+		if !ok {
+			return false
+		}
+		qual += named.Obj().Name() + "."
+	}
+	qual += name
+
+	if qual == sel.qual {
+		return true
+	}
+	// A short selector matches the last element of the package path without dots.
+	last := path.Base(pkg.Path())
+	return sel.isShort() && !strings.Contains(last, ".") && last+qual[len(pkg.Path()):] == sel.qual
+}
+
+// isShort returns true if the package path of the qualified selector has a single element,
+// which also matches the last element of longer paths.
+func (sel funcSelector) isShort() bool {
+	// Type and function names contain no slashes.
+	return !strings.Contains(sel.qual, "/")
+}
+
+// pkgPaths returns the possible package paths of the qualified selector.
+// The selector ends with Func or Type.Method, and the last element of a longer
+// package path may contain dots, so gopkg.in/yaml.v3.Node.Decode is in
+// gopkg.in/yaml.v3.Node or gopkg.in/yaml.v3.
+func (sel funcSelector) pkgPaths() []string {
+	lastSlash := strings.LastIndexByte(sel.qual, '/')
+	parts := strings.Split(sel.qual[lastSlash+1:], ".")
+	if sel.isShort() {
+		return parts[:1]
+	}
+	var paths []string
+	for n := 1; n <= 2 && n < len(parts); n++ {
+		paths = append(paths, sel.qual[:lastSlash+1]+strings.Join(parts[:len(parts)-n], "."))
+	}
+	return paths
+}
+
+// matchFunc returns true if fn is selected.
+func (sel funcSelector) matchFunc(fn *ssa.Function) bool {
+	return sel.match(funcPkg(fn), fn.Name(), fn.Signature)
+}
+
+// funcPkg returns the package fn is declared in.
+func funcPkg(fn *ssa.Function) *types.Package {
+	if origin := fn.Origin(); origin != nil {
+		fn = origin
+	}
+	if fn.Pkg != nil {
+		return fn.Pkg.Pkg
+	}
+	if obj := fn.Object(); obj != nil {
+		return obj.Pkg()
+	}
+	return nil
+}
+
+// checkPkgPath returns true if pkg path is one of paths
+// or if either are nil.
+func checkPkgPath(pkg *types.Package, paths []string) bool {
+	if pkg == nil || paths == nil {
+		return true
+	}
+	path := pkg.Path()
+	for i := len(paths) - 1; i >= 0; i-- {
+		if path == paths[i] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"go/types"
+	"slices"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want funcSelector
+		err  bool
+	}{
+		{in: "Log", want: funcSelector{name: "Log"}},
+		{in: "audit.Log", want: funcSelector{qual: "audit.Log", name: "Log"}},
+		{in: "github.com/acme/audit.Log", want: funcSelector{qual: "github.com/acme/audit.Log", name: "Log"}},
+		{in: "github.com/acme/api.Handler.ServeHTTP", want: funcSelector{qual: "github.com/acme/api.Handler.ServeHTTP", name: "ServeHTTP"}},
+		{in: "(github.com/acme/db.Tx).Commit", want: funcSelector{qual: "github.com/acme/db.Tx.Commit", name: "Commit"}},
+		{in: "(*github.com/acme/db.Tx).Commit", want: funcSelector{qual: "github.com/acme/db.Tx.Commit", ptr: true, name: "Commit"}},
+		{in: "gopkg.in/yaml.v3.Unmarshal", want: funcSelector{qual: "gopkg.in/yaml.v3.Unmarshal", name: "Unmarshal"}},
+		{in: "gopkg.in/yaml.v3.Node.Decode", want: funcSelector{qual: "gopkg.in/yaml.v3.Node.Decode", name: "Decode"}},
+		{in: "(*gopkg.in/yaml.v3.Node).Decode", want: funcSelector{qual: "gopkg.in/yaml.v3.Node.Decode", ptr: true, name: "Decode"}},
+		{in: "github.com/acme/audit", err: true},
+		{in: "gopkg.in/yaml.v3.", err: true},
+		{in: "audit.", err: true},
+		{in: "a.b.c.d", err: true},
+		{in: "(*Tx).Commit", err: true},
+		{in: "(*db.Tx)", err: true},
+	} {
+		got, err := parseSelector(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSelectorPkgPaths(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want []string
+	}{
+		{in: "audit.Log", want: []string{"audit"}},
+		{in: "audit.Logger.Log", want: []string{"audit"}},
+		{in: "github.com/acme/audit.Log", want: []string{"github.com/acme/audit"}},
+		{in: "github.com/acme/audit.Logger.Log", want: []string{"github.com/acme/audit.Logger", "github.com/acme/audit"}},
+		{in: "gopkg.in/yaml.v3.Unmarshal", want: []string{"gopkg.in/yaml.v3", "gopkg.in/yaml"}},
+		{in: "gopkg.in/yaml.v3.Node.Decode", want: []string{"gopkg.in/yaml.v3.Node", "gopkg.in/yaml.v3"}},
+	} {
+		sel, err := parseSelector(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if got := sel.pkgPaths(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSelectorMatchDottedPkg(t *testing.T) {
+	yaml := types.NewPackage("gopkg.in/yaml.v3", "yaml")
+	node := types.NewNamed(types.NewTypeName(0, yaml, "Node", nil), types.NewStruct(nil, nil), nil)
+	recv := types.NewVar(0, yaml, "n", types.NewPointer(node))
+	decode := types.NewSignatureType(recv, nil, nil, nil, nil, false)
+	unmarshal := types.NewSignatureType(nil, nil, nil, nil, nil, false)
+
+	for _, tt := range []struct {
+		sel  string
+		name string
+		sig  *types.Signature
+		want bool
+	}{
+		{sel: "gopkg.in/yaml.v3.Node.Decode", name: "Decode", sig: decode, want: true},
+		{sel: "(*gopkg.in/yaml.v3.Node).Decode", name: "Decode", sig: decode, want: true},
+		{sel: "gopkg.in/yaml.v3.Unmarshal", name: "Unmarshal", sig: unmarshal, want: true},
+		{sel: "yaml.v3.Unmarshal", name: "Unmarshal", sig: unmarshal, want: false},
+		{sel: "gopkg.in/yaml.v2.Unmarshal", name: "Unmarshal", sig: unmarshal, want: false},
+	} {
+		sel, err := parseSelector(tt.sel)
+		if err != nil {
+			t.Errorf("%s: %v", tt.sel, err)
+			continue
+		}
+		if got := sel.match(yaml, tt.name, tt.sig); got != tt.want {
+			t.Errorf("%s: match = %v, want %v", tt.sel, got, tt.want)
+		}
+	}
+}
+
+func TestRequiresDottedPkg(t *testing.T) {
+	r, err := compileRule(&Rule{
+		Name:   "decode",
+		Callee: CalleeOpts{Name: "Decode", PkgPaths: []string{"gopkg.in/yaml.v3"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		sel  string
+		want bool
+	}{
+		{sel: "Decode", want: true},
+		{sel: "gopkg.in/yaml.v3.Node.Decode", want: true},
+		{sel: "(*gopkg.in/yaml.v3.Node).Decode", want: true},
+		{sel: "gopkg.in/yaml.v2.Node.Decode", want: false},
+	} {
+		sel, err := parseSelector(tt.sel)
+		if err != nil {
+			t.Errorf("%s: %v", tt.sel, err)
+			continue
+		}
+		if got := r.requires(sel); got != tt.want {
+			t.Errorf("%s: requires = %v, want %v", tt.sel, got, tt.want)
+		}
+	}
+}
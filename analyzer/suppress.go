@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// suppression silences diagnostics of a rule.
+//
+// Suppressions are written as
+//
+//	//sadboy:ignore <rule> <reason>
+//	//nolint:sadboy // <reason>
+//
+// with the name of the analyzer instead of sadboy, see [directivePrefix],
+// in the doc comment of a function, silencing all diagnostics in the function,
+// or on any other line, silencing the diagnostics on that line.
+// nolint silences all rules.
+type suppression struct {
+	pos token.Pos
+
+	// Directive as written, used in messages.
+	name string
+
+	// Rule name, "" for all rules.
+	rule string
+
+	// Silenced range, if the suppression is in the doc comment of a function.
+	start, end token.Pos
+
+	// Silenced line, if the suppression is not in a doc comment.
+	file *token.File
+	line int
+
+	used bool
+}
+
+// covers returns true if the suppression silences a diagnostic of r at pos.
+func (s *suppression) covers(fset *token.FileSet, r *rule, pos token.Pos) bool {
+	if s.rule != "" && s.rule != r.Name {
+		return false
+	}
+	if s.start.IsValid() {
+		return s.start <= pos && pos < s.end
+	}
+	return fset.File(pos) == s.file && s.file.Line(pos) == s.line
+}
+
+// reporter reports the diagnostics of rules,
+// unless they are suppressed or in the baseline.
+type reporter struct {
+	pass         *analysis.Pass
+	suppressions []*suppression
+
+	// Optional baseline.
+	baseline *baseline
+
+	// Findings of the package, if the baseline is updated.
+	findings []Finding
+}
+
+// newReporter collects the suppressions in the files of the package.
+// Invalid suppressions are reported.
+func newReporter(pass *analysis.Pass, rules []*rule, bl *baseline) *reporter {
+	rep := &reporter{pass: pass, baseline: bl}
+	for _, f := range pass.Files {
+		// Suppressions in doc comments apply to the whole function.
+		docs := make(map[*ast.CommentGroup]*ast.FuncDecl)
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Doc != nil {
+				docs[fn.Doc] = fn
+			}
+		}
+
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				s, err := parseSuppression(c, pass.Analyzer.Name)
+				if err != nil {
+					pass.Reportf(c.Pos(), "%s", err)
+					continue
+				}
+				if s == nil {
+					continue
+				}
+				if s.rule != "" && !slices.ContainsFunc(rules, func(r *rule) bool { return r.Name == s.rule }) {
+					pass.Reportf(c.Pos(), "%s directive: unknown rule %s", s.name, s.rule)
+					continue
+				}
+
+				if fn, ok := docs[group]; ok {
+					s.start, s.end = fn.Pos(), fn.End()
+				} else {
+					s.file = pass.Fset.File(c.Pos())
+					s.line = s.file.Line(c.Pos())
+				}
+				rep.suppressions = append(rep.suppressions, s)
+			}
+		}
+	}
+	return rep
+}
+
+// parseSuppression parses c as a suppression for the analyzer name.
+// Returns nil if c is no suppression.
+func parseSuppression(c *ast.Comment, name string) (*suppression, error) {
+	if text, ok := strings.CutPrefix(c.Text, directivePrefix(name)); ok {
+		text, _, _ = strings.Cut(text, "//")
+		fields := strings.Fields(text)
+		if len(fields) == 0 || fields[0] != "ignore" {
+			return nil, nil
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%s:ignore directive requires a rule and a reason", name)
+		}
+		return &suppression{
+			pos:  c.Pos(),
+			name: name + ":ignore",
+			rule: fields[1],
+		}, nil
+	}
+
+	text, ok := strings.CutPrefix(c.Text, "//nolint:")
+	if !ok {
+		return nil, nil
+	}
+	linters, reason, _ := strings.Cut(text, "//")
+	linters, _, _ = strings.Cut(linters, " ")
+	if !slices.Contains(strings.Split(linters, ","), name) {
+		return nil, nil
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("nolint:%s directive requires a reason (//nolint:%s // reason)", name, name)
+	}
+	return &suppression{
+		pos:  c.Pos(),
+		name: "nolint:" + name,
+	}, nil
+}
+
+// report reports the diagnostic of r, unless it is suppressed.
+func (rep *reporter) report(r *rule, d analysis.Diagnostic) {
+	d.Category = r.Name
+	if !rep.suppressed(r, d.Pos) {
+		rep.pass.Report(d)
+	}
+}
+
+// reportFinding reports the diagnostic of caller violating r,
+// unless it is suppressed or in the baseline.
+func (rep *reporter) reportFinding(r *rule, caller *ssa.Function, d analysis.Diagnostic) {
+	d.Category = r.Name
+	if rep.suppressed(r, d.Pos) {
+		return
+	}
+	if bl := rep.baseline; bl != nil {
+		f := Finding{
+			Rule:    r.Name,
+			Package: rep.pass.Pkg.Path(),
+			Func:    caller.RelString(rep.pass.Pkg),
+		}
+		if bl.update {
+			rep.findings = append(rep.findings, f)
+			return
+		}
+		if _, ok := bl.known[f]; ok {
+			return
+		}
+	}
+	rep.pass.Report(d)
+}
+
+// suppressed returns true if a diagnostic of r at pos is suppressed.
+// All suppressions covering the diagnostic are marked as used.
+func (rep *reporter) suppressed(r *rule, pos token.Pos) bool {
+	suppressed := false
+	for _, s := range rep.suppressions {
+		if s.covers(rep.pass.Fset, r, pos) {
+			s.used = true
+			suppressed = true
+		}
+	}
+	return suppressed
+}
+
+// finish reports unused suppressions and updates the baseline, if requested.
+func (rep *reporter) finish() error {
+	rep.reportUnused()
+	if rep.baseline == nil || !rep.baseline.update || !rep.baseline.writes(rep.pass) {
+		return nil
+	}
+	return updateBaseline(rep.baseline.path, rep.pass.Pkg.Path(), rep.findings)
+}
+
+// reportUnused reports all suppressions that did not silence any diagnostic.
+func (rep *reporter) reportUnused() {
+	for _, s := range rep.suppressions {
+		if s.used {
+			continue
+		}
+		if s.rule != "" {
+			rep.pass.Reportf(s.pos, "unused %s directive for rule %s", s.name, s.rule)
+		} else {
+			rep.pass.Reportf(s.pos, "unused %s directive", s.name)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package api // want package:"summary"
+
+import "allpaths/audit"
+
+func HandleOK(ok bool) int { // OK: Log before every return
+	audit.Log()
+	if ok {
+		return 1
+	}
+	return 2
+}
+
+func HandleEarly(ok bool) int {
+	if !ok {
+		return 1 // want "HandleEarly returns without calling callee function"
+	}
+	audit.Log()
+	return 2
+}
+
+func HandleImplicit(ok bool) {
+	if ok {
+		audit.Log()
+	}
+} // want "HandleImplicit returns without calling callee function"
+
+func HandleNever() int {
+	return 1 // want "HandleNever returns without calling callee function"
+}
+
+func HandleBranches(ok bool) int { // OK: Log on both branches
+	if ok {
+		audit.Log()
+	} else {
+		helper()
+	}
+	return 1
+}
+
+func helper() {
+	audit.Log()
+}
+
+func HandleDefer(ok bool) int { // OK: deferred before every return
+	defer audit.Log()
+	if ok {
+		return 1
+	}
+	return 2
+}
+
+func HandleDeferLate(ok bool) int {
+	if ok {
+		return 1 // want "HandleDeferLate returns without calling callee function"
+	}
+	defer audit.Log()
+	return 2
+}
+
+func HandleDeferClosure(ok bool) int { // OK: deferred closure calls Log
+	defer func() {
+		audit.Log()
+	}()
+	if ok {
+		return 1
+	}
+	return 2
+}
+
+func HandleLoop(n int) {
+	for i := 0; i < n; i++ {
+		audit.Log()
+	}
+} // want "HandleLoop returns without calling callee function"
+
+func HandlePanic(ok bool) { // OK: panics are no returns
+	if !ok {
+		panic("not ok")
+	}
+	audit.Log()
+}
+
+func HandleRecover() { // OK: returns after a recovered panic are ignored
+	defer func() {
+		_ = recover()
+	}()
+	audit.Log()
+}
+
+func HandleGo() {
+	go audit.Log()
+} // want "HandleGo returns without calling callee function"
+
+func HandleConditionalCallee(ok bool) { // OK: Logged reaches Log
+	audit.Logged(ok)
+}
+
+func HandleInterface(l audit.Logger) {
+	l.Log()
+} // want "HandleInterface returns without calling callee function"
+
+func HandleInterfaceAll() { // OK: Audit calls Log
+	var l audit.Logger = audit.Audit{}
+	l.Log()
+}
+
+func use() {
+	HandleInterface(audit.Noop{})
+	HandleInterface(audit.Audit{})
+}
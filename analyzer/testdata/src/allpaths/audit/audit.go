@@ -0,0 +1,25 @@
+package audit // want package:"summary"
+
+func Log() {
+}
+
+// Logged calls Log on some paths.
+func Logged(ok bool) {
+	if ok {
+		Log()
+	}
+}
+
+type Logger interface {
+	Log()
+}
+
+type Noop struct{}
+
+func (Noop) Log() {}
+
+type Audit struct{}
+
+func (Audit) Log() {
+	Log()
+}
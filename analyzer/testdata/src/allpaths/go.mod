@@ -0,0 +1,3 @@
+module allpaths
+
+go 1.22.0
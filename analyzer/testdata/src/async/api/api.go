@@ -0,0 +1,46 @@
+package api // want package:"summary"
+
+import "async/audit"
+
+var jobs = make(chan func(), 16)
+
+func init() {
+	go worker()
+}
+
+func worker() {
+	for job := range jobs {
+		job()
+	}
+}
+
+func HandleSend() { // OK: the worker calls Log
+	jobs <- func() {
+		audit.Log()
+	}
+}
+
+func HandleSend_fail() { // want "HandleSend_fail does not call callee function"
+	jobs <- func() {}
+}
+
+func HandleSendFunc() { // OK: the worker calls Log
+	jobs <- audit.Log
+}
+
+func HandleGo() { // OK: go statements count
+	go audit.Log()
+}
+
+func HandleCall() { // OK
+	audit.Log()
+}
+
+var results = make(chan func(int), 16)
+
+func HandleResult_fail() { // want "HandleResult_fail does not call callee function"
+	// Nothing calls the functions received from results.
+	results <- func(int) {
+		audit.Log()
+	}
+}
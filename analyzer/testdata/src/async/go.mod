@@ -0,0 +1,3 @@
+module async
+
+go 1.22.0
@@ -0,0 +1,29 @@
+package gostmt // want package:"summary"
+
+import "async/audit"
+
+var jobs = make(chan func(), 16)
+
+func init() {
+	go worker()
+}
+
+func worker() {
+	for job := range jobs {
+		job()
+	}
+}
+
+func HandleSend() { // OK: functions sent over channels still count
+	jobs <- func() {
+		audit.Log()
+	}
+}
+
+func HandleGo() { // want "HandleGo does not call callee function"
+	go audit.Log()
+}
+
+func HandleCall() { // OK
+	audit.Log()
+}
@@ -0,0 +1,44 @@
+package strict // want package:"summary"
+
+import "async/audit"
+
+var jobs = make(chan func(), 16)
+
+func init() {
+	go worker()
+}
+
+func worker() {
+	for job := range jobs {
+		job()
+	}
+}
+
+func HandleSend() { // want "HandleSend does not call callee function"
+	jobs <- func() {
+		audit.Log()
+	}
+}
+
+func HandleGo() { // want "HandleGo does not call callee function"
+	go audit.Log()
+}
+
+func HandleGoFunc() { // want "HandleGoFunc does not call callee function"
+	go func() {
+		audit.Log()
+	}()
+}
+
+func HandleCall() { // OK: only direct calls count
+	audit.Log()
+}
+
+func HandleWait() { // OK: the goroutine is not the caller
+	done := make(chan struct{})
+	go func() {
+		close(done)
+	}()
+	<-done
+	audit.Log()
+}
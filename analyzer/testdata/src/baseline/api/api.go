@@ -0,0 +1,22 @@
+package api // want package:"summary"
+
+type Server struct{}
+
+func Log() {
+}
+
+func HandleKnown() { // OK: in baseline
+}
+
+func (*Server) HandleKnown() { // OK: in baseline
+}
+
+func HandleNew() { // want "HandleNew does not call callee function"
+}
+
+func (Server) HandleNew() { // want "HandleNew does not call callee function"
+}
+
+func HandleOK() {
+	Log()
+}
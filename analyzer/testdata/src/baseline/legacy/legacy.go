@@ -0,0 +1,15 @@
+package legacy // want package:"summary"
+
+import "baseline/api"
+
+type Server struct{}
+
+func HandleA() { // OK: written to baseline
+}
+
+func (*Server) HandleB() { // OK: written to baseline
+}
+
+func HandleOK() {
+	api.Log()
+}
@@ -0,0 +1,36 @@
+package caller // want package:"summary"
+
+import (
+	"calleepkg/audit"
+	"calleepkg/db"
+	fake "calleepkg/fake/audit"
+	nested "calleepkg/fake/calleepkg/audit"
+)
+
+type Param string
+
+func Log(s Param) { // want "Log: commit"
+	audit.Log()
+}
+
+func LogFake(s Param) { // want "LogFake: audit" "LogFake: commit" "LogFake: qualified"
+	fake.Log()
+}
+
+func LogNested(s Param) { // want "LogNested: audit" "LogNested: commit" "LogNested: qualified"
+	nested.Log()
+}
+
+func Commit(s Param) { // want "Commit: audit" "Commit: qualified"
+	tx := &db.Tx{}
+	tx.Commit()
+}
+
+func CommitOther(s Param) { // want "CommitOther: audit" "CommitOther: commit" "CommitOther: qualified"
+	o := &db.Other{}
+	o.Commit()
+}
+
+func CommitFunc(s Param) { // want "CommitFunc: audit" "CommitFunc: commit" "CommitFunc: qualified"
+	db.Commit()
+}
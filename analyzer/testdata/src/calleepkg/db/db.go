@@ -0,0 +1,14 @@
+package db // want package:"summary"
+
+type Tx struct{}
+
+func (tx *Tx) Commit() {
+}
+
+type Other struct{}
+
+func (o *Other) Commit() {
+}
+
+func Commit() {
+}
@@ -0,0 +1,3 @@
+module calleepkg
+
+go 1.22.0
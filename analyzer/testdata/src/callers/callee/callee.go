@@ -1,4 +1,4 @@
-package callee // want package:"types"
+package callee // want package:"summary"
 
 func Callee() {
 }
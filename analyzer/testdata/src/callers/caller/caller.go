@@ -1,4 +1,4 @@
-package caller // want package:"types"
+package caller // want package:"summary"
 
 import (
 	"callers/other"
@@ -110,7 +110,7 @@ func Test8(s Param) Result { // OK: calls Callee (function)
 	return nil
 }
 
-func (d *Dummy) test8() { // want "Test7_fail does not call callee function"
+func (d *Dummy) test8() { // OK: (helper) does not match Caller
 	other.CallCallee()
 }
 
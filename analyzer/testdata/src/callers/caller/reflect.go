@@ -2,11 +2,16 @@ package caller
 
 import "callers/other"
 
-func Reflect1(s Param) Result { // want "Reflect1 does not call callee function"
+func Reflect1(s Param) Result { // OK: calls Callee through a function asserted from an interface
 	reflect1(other.CallCallee)
 	return nil
 }
 
+func Reflect1_fail(s Param) Result { // want "Reflect1_fail does not call callee function"
+	reflect1(other.DoNotCallCallee)
+	return nil
+}
+
 func reflect1(f any) {
 	if fn, ok := f.(func()); ok {
 		fn()
@@ -1,4 +1,4 @@
-package other // want package:"types"
+package other // want package:"summary"
 
 import "callers/callee"
 
@@ -0,0 +1,17 @@
+package caller // want package:"summary"
+
+import "config/lib"
+
+type Request string
+
+func Handle(r Request) { // OK: logs and does not exit
+	lib.Log()
+}
+
+func HandleExit(r Request) { // want `error: HandleExit calls forbidden function Exit: HandleExit -> config/lib.Exit \(caller.go:13\)`
+	lib.Log()
+	lib.Exit()
+}
+
+func HandleNoLog(r Request) { // want `HandleNoLog must call Log \(audit\)`
+}
@@ -0,0 +1,4 @@
+package caller
+
+func HandleSkipped(r Request) { // OK: file is skipped
+}
@@ -0,0 +1,7 @@
+package lib // want package:"summary"
+
+func Log() {
+}
+
+func Exit() {
+}
@@ -0,0 +1,46 @@
+package api // want package:"summary"
+
+import (
+	"deferred/helper"
+	"deferred/span"
+)
+
+func HandleDefer() { // OK: defers End
+	span.Start()
+	defer span.End()
+}
+
+func HandleDefer_fail() { // want "HandleDefer_fail does not defer callee function"
+	span.Start()
+	span.End()
+}
+
+func HandleFinish() { // OK: defers finish calling End
+	defer finish()
+}
+
+func HandleFinish_fail() { // want "HandleFinish_fail does not defer callee function"
+	finish()
+}
+
+func finish() {
+	span.End()
+}
+
+func HandleExternal() { // OK: defers helper.Finish calling End
+	defer helper.Finish()
+}
+
+func HandleExternal_fail() { // want "HandleExternal_fail does not defer callee function"
+	helper.Finish()
+}
+
+func HandleCleanup() { // OK: helper.Cleanup defers End
+	helper.Cleanup()
+}
+
+func HandleClosure() { // OK: the deferred closure calls End
+	defer func() {
+		span.End()
+	}()
+}
@@ -0,0 +1,31 @@
+package direct // want package:"summary"
+
+import (
+	"deferred/helper"
+	"deferred/span"
+)
+
+func HandleDirect() { // OK: calls End directly
+	span.End()
+}
+
+func HandleDirect_fail() { // want "HandleDirect_fail does not call callee function without defer"
+	defer span.End()
+}
+
+func HandleBoth() { // OK: one of the calls is direct
+	defer span.End()
+	span.End()
+}
+
+func HandleFinish() { // OK: helper.Finish calls End directly
+	helper.Finish()
+}
+
+func HandleFinish_fail() { // want "HandleFinish_fail does not call callee function without defer"
+	defer helper.Finish()
+}
+
+func HandleCleanup_fail() { // want "HandleCleanup_fail does not call callee function without defer"
+	helper.Cleanup()
+}
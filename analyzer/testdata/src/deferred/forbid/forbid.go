@@ -0,0 +1,24 @@
+package forbid // want package:"summary"
+
+import (
+	"deferred/helper"
+	"deferred/span"
+)
+
+func HandleLoop() { // OK: End is not deferred
+	for range 3 {
+		span.Start()
+		span.End()
+	}
+}
+
+func HandleLoop_fail() { // want "HandleLoop_fail calls forbidden function End"
+	for range 3 {
+		span.Start()
+		defer span.End()
+	}
+}
+
+func HandleCleanup_fail() { // want "HandleCleanup_fail calls forbidden function End"
+	helper.Cleanup()
+}
@@ -0,0 +1,14 @@
+package helper // want package:"summary"
+
+import "deferred/span"
+
+// Finish calls End directly.
+func Finish() {
+	span.End()
+}
+
+// Cleanup defers End.
+func Cleanup() {
+	defer span.End()
+	span.Start()
+}
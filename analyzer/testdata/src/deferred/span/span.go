@@ -0,0 +1,7 @@
+package span // want package:"summary"
+
+func Start() {
+}
+
+func End() {
+}
@@ -0,0 +1,58 @@
+package api // want package:"summary"
+
+import "directive/audit"
+
+//sadboy:require audit.Log
+func Require() { // want "Require does not call directive/audit.Log"
+}
+
+//sadboy:require directive/audit.Log
+func RequireOK() { // OK: calls Log
+	audit.Log()
+}
+
+//sadboy:rule trace
+func Rule() { // want "Rule does not call Trace"
+	audit.Log()
+}
+
+// RuleOK is documented.
+//
+//sadboy:rule trace
+func RuleOK() { // OK: calls Trace
+	audit.Trace()
+}
+
+//sadboy:rule trace
+//sadboy:require audit.Log
+func Both() { // want "Both does not call Trace"
+	audit.Log()
+}
+
+func NotAnnotated() { // OK: no directive
+}
+
+//sadboy:require other.Log // want "sadboy:require directive: no rule for other.Log"
+func Unknown() {
+}
+
+//sadboy:rule // want "sadboy:rule directive requires one argument"
+func NoArg() {
+}
+
+//sadboy:requires audit.Log // want "unknown directive sadboy:requires"
+func Typo() {
+}
+
+//sadboy:require directive/audit.Event
+func RequireEvent() { // want "RequireEvent does not call Event"
+}
+
+//sadboy:require directive/audit.Event
+func RequireEventOK() { // OK: calls Event
+	audit.Event()
+}
+
+//sadboy:require other/audit.Event // want "sadboy:require directive: no rule for other/audit.Event"
+func RequireEventOther() {
+}
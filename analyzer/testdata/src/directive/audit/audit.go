@@ -0,0 +1,10 @@
+package audit // want package:"summary"
+
+func Log() {
+}
+
+func Trace() {
+}
+
+func Event() {
+}
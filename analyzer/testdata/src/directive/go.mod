@@ -0,0 +1,3 @@
+module directive
+
+go 1.22.0
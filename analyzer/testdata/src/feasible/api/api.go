@@ -0,0 +1,51 @@
+package api // want package:"summary"
+
+import (
+	"feasible/audit"
+	"feasible/lib"
+)
+
+// The closures and loggers of all handlers flow into the same library functions,
+// each handler only calls its own.
+
+func HandleApply() { // OK: the closure passed to Apply calls Log
+	lib.Apply(func() {
+		audit.Log()
+	})
+}
+
+func HandleApply_fail() { // want "HandleApply_fail does not call callee function"
+	lib.Apply(func() {})
+}
+
+func HandleSort() { // OK: the closure passed to SortFunc calls Log
+	lib.SortFunc([]int{2, 1}, func(a, b int) int {
+		audit.Log()
+		return a - b
+	})
+}
+
+func HandleSort_fail() { // want "HandleSort_fail does not call callee function"
+	lib.SortFunc([]int{2, 1}, func(a, b int) int {
+		return a - b
+	})
+}
+
+type logger struct{}
+
+func (logger) Log() {
+	audit.Log()
+}
+
+type noop struct{}
+
+func (noop) Log() {
+}
+
+func HandleUse() { // OK: the logger passed to Use calls Log
+	lib.Use(logger{})
+}
+
+func HandleUse_fail() { // want "HandleUse_fail does not call callee function"
+	lib.Use(noop{})
+}
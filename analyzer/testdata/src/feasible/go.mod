@@ -0,0 +1,3 @@
+module feasible
+
+go 1.22.0
@@ -0,0 +1,36 @@
+package lib // want package:"summary"
+
+// Apply calls f.
+func Apply(f func()) {
+	apply(f)
+}
+
+func apply(f func()) {
+	f()
+}
+
+// SortFunc sorts s like slices.SortFunc.
+func SortFunc[S ~[]E, E any](s S, cmp func(a, b E) int) {
+	insertionSort(s, cmp)
+}
+
+func insertionSort[S ~[]E, E any](s S, cmp func(a, b E) int) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && cmp(s[j], s[j-1]) < 0; j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
+
+type Logger interface {
+	Log()
+}
+
+// Use calls the method of l.
+func Use(l Logger) {
+	use(l)
+}
+
+func use(l Logger) {
+	l.Log()
+}
@@ -0,0 +1,69 @@
+package api // want package:"summary"
+
+import (
+	"flow/audit"
+	"flow/ctx"
+)
+
+func HandleOK(c ctx.Context) { // OK: passes its context
+	audit.Log(c, "ok")
+}
+
+func HandleBackground(c ctx.Context) { // want `HandleBackground calls Log without passing its parameter: HandleBackground -> flow/audit.Log \(api.go:13\)`
+	audit.Log(ctx.Background(), "background")
+}
+
+func HandleDerived(c ctx.Context) { // OK: derived context
+	audit.Log(ctx.WithValue(c, "key", "value"), "derived")
+}
+
+func HandlePhi(c ctx.Context, ok bool) { // OK: derived on some branches
+	if ok {
+		c = ctx.WithValue(c, "key", "value")
+	}
+	audit.Log(c, "phi")
+}
+
+func HandleEither(c ctx.Context, ok bool) { // OK: passes its context on some path
+	if ok {
+		audit.Log(ctx.Background(), "background")
+		return
+	}
+	audit.Log(c, "either")
+}
+
+func HandleHelper(c ctx.Context) { // OK: helper passes the context
+	helper(c)
+}
+
+func HandleHelperBackground(c ctx.Context) { // want "HandleHelperBackground calls Log without passing its parameter"
+	helper(ctx.Background())
+}
+
+func helper(c ctx.Context) {
+	audit.Log(c, "helper")
+}
+
+func HandleWrapper(c ctx.Context) { // OK: Logf passes its context
+	audit.Logf(c, "wrapper")
+}
+
+func HandleWrapperBackground(c ctx.Context) { // want "HandleWrapperBackground calls Log without passing its parameter"
+	audit.LogBackground("wrapper")
+}
+
+func HandleClosure(c ctx.Context) { // OK: captured by the closure
+	func() {
+		audit.Log(c, "closure")
+	}()
+}
+
+func HandleCaptured(c ctx.Context) { // OK: captured variable
+	c = ctx.WithValue(c, "key", "value")
+	func() {
+		audit.Log(c, "captured")
+	}()
+}
+
+func HandleNoLog(c ctx.Context) { // want "HandleNoLog does not call callee function"
+}
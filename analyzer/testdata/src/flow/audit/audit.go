@@ -0,0 +1,16 @@
+package audit // want package:"summary"
+
+import "flow/ctx"
+
+func Log(c ctx.Context, msg string) {
+}
+
+// Logf passes its context to Log.
+func Logf(c ctx.Context, msg string) {
+	Log(c, msg)
+}
+
+// LogBackground logs without a context.
+func LogBackground(msg string) {
+	Log(ctx.Background(), msg)
+}
@@ -0,0 +1,24 @@
+package ctx // want package:"summary"
+
+type Context interface {
+	Value(key any) any
+}
+
+type emptyCtx struct{}
+
+func (emptyCtx) Value(any) any {
+	return nil
+}
+
+func Background() Context {
+	return emptyCtx{}
+}
+
+type valueCtx struct {
+	Context
+	key, val any
+}
+
+func WithValue(parent Context, key, val any) Context {
+	return &valueCtx{parent, key, val}
+}
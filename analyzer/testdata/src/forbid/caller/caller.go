@@ -0,0 +1,30 @@
+package caller // want package:"summary"
+
+import "forbid/exit"
+
+type Param string
+type Result error
+
+func Direct(s Param) Result { // want `Direct calls forbidden function Exit: Direct -> forbid/exit.Exit \(caller.go:9\)`
+	exit.Exit(1)
+	return nil
+}
+
+func Fatal(s Param) Result { // want `Fatal calls forbidden function Exit: Fatal -> forbid/exit.Fatal \(caller.go:14\) -> forbid/exit.Exit \(exit.go:7\)`
+	exit.Fatal("fatal")
+	return nil
+}
+
+func Helper(s Param) Result { // want `Helper calls forbidden function Exit: Helper -> helper \(caller.go:19\) -> forbid/exit.Exit \(caller.go:24\)`
+	helper()
+	return nil
+}
+
+func helper() {
+	exit.Exit(1)
+}
+
+func OK(s Param) Result { // OK: does not exit
+	exit.Print("ok")
+	return nil
+}
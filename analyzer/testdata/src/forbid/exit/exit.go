@@ -0,0 +1,11 @@
+package exit // want package:"summary"
+
+func Exit(code int) {
+}
+
+func Fatal(msg string) {
+	Exit(1)
+}
+
+func Print(msg string) {
+}
@@ -0,0 +1,3 @@
+module forbid
+
+go 1.22.0
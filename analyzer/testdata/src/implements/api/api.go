@@ -0,0 +1,25 @@
+package api // want package:"summary"
+
+import "implements/iface"
+
+type A struct{}
+
+func (a *A) ServeHTTP(w iface.Writer) { // want "ServeHTTP does not call callee function"
+}
+
+func (a *A) Other() { // OK: not in interface
+}
+
+type B struct{}
+
+func (b B) ServeHTTP(w iface.Writer) { // OK: calls Log
+	iface.Log()
+}
+
+type C struct{}
+
+func (c C) ServeHTTP() { // OK: does not implement the interface
+}
+
+func ServeHTTP(w iface.Writer) { // OK: not a method
+}
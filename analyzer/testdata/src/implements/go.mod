@@ -0,0 +1,3 @@
+module implements
+
+go 1.22.0
@@ -0,0 +1,12 @@
+package iface // want package:"summary"
+
+type Writer interface {
+	Write(b []byte)
+}
+
+type Handler interface {
+	ServeHTTP(w Writer)
+}
+
+func Log() {
+}
@@ -0,0 +1,27 @@
+package api // want package:"summary"
+
+func Log() {
+}
+
+func HandleUser() { // OK: calls Log
+	Log()
+}
+
+func HandleOrder() { // want "HandleOrder does not call callee function"
+}
+
+func Handler() { // OK: does not match regex
+}
+
+func handleInternal() { // OK: does not match regex
+}
+
+func CreateCommand() { // OK: calls Log
+	Log()
+}
+
+func DeleteCommand() { // want "DeleteCommand does not call callee function"
+}
+
+func Command() { // OK: does not match glob
+}
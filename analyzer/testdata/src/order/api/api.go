@@ -0,0 +1,60 @@
+package api // want package:"summary"
+
+import (
+	"order/authz"
+	"order/db"
+)
+
+func HandleOK() { // OK: Check before Exec
+	authz.Check()
+	db.Exec()
+}
+
+func HandleBefore() {
+	db.Exec() // want `HandleBefore calls Exec before Check: HandleBefore -> order/db.Exec \(api.go:14\)`
+	authz.Check()
+}
+
+func HandleIndirect() {
+	db.Query() // want `HandleIndirect calls Exec before Check: HandleIndirect -> order/db.Query \(api.go:19\) -> order/db.Exec \(db.go:9\)`
+	authz.Check()
+}
+
+func HandleBranch(ok bool) {
+	if ok {
+		authz.Check()
+	}
+	db.Exec() // want "HandleBranch calls Exec before Check"
+}
+
+func HandleBothBranches(ok bool) { // OK: checked on both branches
+	if ok {
+		authz.Check()
+	} else {
+		check()
+	}
+	db.Exec()
+}
+
+func check() {
+	authz.Check()
+}
+
+func HandleDefer() {
+	defer authz.Check()
+	db.Exec() // want "HandleDefer calls Exec before Check"
+}
+
+func HandleCheckedExec() { // OK: CheckedExec checks first
+	db.CheckedExec()
+}
+
+func HandleNoExec() { // OK: never calls Exec
+}
+
+func HandleLoop(n int) {
+	for i := 0; i < n; i++ {
+		db.Exec() // want "HandleLoop calls Exec before Check"
+		authz.Check()
+	}
+}
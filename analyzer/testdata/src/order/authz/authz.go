@@ -0,0 +1,4 @@
+package authz // want package:"summary"
+
+func Check() {
+}
@@ -0,0 +1,16 @@
+package db // want package:"summary"
+
+import "order/authz"
+
+func Exec() {
+}
+
+func Query() {
+	Exec()
+}
+
+// CheckedExec checks before executing.
+func CheckedExec() {
+	authz.Check()
+	Exec()
+}
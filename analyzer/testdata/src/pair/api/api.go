@@ -0,0 +1,83 @@
+package api // want package:"summary"
+
+import "pair/db"
+
+func HandleCommit() { // OK: committed
+	tx := db.Begin()
+	tx.Commit()
+}
+
+func HandleDefer(ok bool) { // OK: rolled back by defer
+	tx := db.Begin()
+	defer tx.Rollback()
+	if ok {
+		tx.Commit()
+	}
+}
+
+func HandleLeak() {
+	tx := db.Begin() // want `HandleLeak calls Begin without calling \(\*pair/db.Tx\).Commit\|\(\*pair/db.Tx\).Rollback: HandleLeak -> pair/db.Begin \(api.go:19\)`
+	_ = tx
+}
+
+func HandleBranch(ok bool) {
+	tx := db.Begin() // want "HandleBranch calls Begin without calling"
+	if ok {
+		tx.Commit()
+	}
+}
+
+func HandleBefore() {
+	var tx *db.Tx
+	tx.Rollback()
+	tx = db.Begin() // want "HandleBefore calls Begin without calling"
+	_ = tx
+}
+
+func HandleLoop(n int) {
+	var tx *db.Tx
+	for i := 0; i < n; i++ {
+		if tx != nil {
+			tx.Commit()
+		}
+		tx = db.Begin() // want "HandleLoop calls Begin without calling"
+	}
+}
+
+func HandleTransact() { // OK: Transact begins and commits
+	db.Transact(func(tx *db.Tx) {})
+}
+
+func HandleWrapped() {
+	tx := db.BeginWrapped() // want `HandleWrapped calls Begin without calling .*: HandleWrapped -> pair/db.BeginWrapped \(api.go:52\) -> pair/db.Begin \(db.go:24\)`
+	release(tx)
+}
+
+func HandleHelper() { // OK: released by helper
+	tx := db.Begin()
+	commit(tx)
+}
+
+func commit(tx *db.Tx) {
+	tx.Commit()
+}
+
+func release(*db.Tx) {
+}
+
+func HandleBoth(ok bool) { // OK: committed or rolled back on every path
+	tx := db.Begin()
+	if ok {
+		tx.Commit()
+		return
+	}
+	tx.Rollback()
+}
+
+func HandlePanic(ok bool) { // OK: the path without commit panics
+	tx := db.Begin()
+	if !ok {
+		panic("not ok")
+	}
+	tx.Commit()
+}
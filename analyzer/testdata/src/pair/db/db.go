@@ -0,0 +1,25 @@
+package db // want package:"summary"
+
+type Tx struct{}
+
+func Begin() *Tx {
+	return &Tx{}
+}
+
+func (*Tx) Commit() {
+}
+
+func (*Tx) Rollback() {
+}
+
+// Transact runs fn in a transaction.
+func Transact(fn func(*Tx)) {
+	tx := Begin()
+	fn(tx)
+	tx.Commit()
+}
+
+// BeginWrapped wraps Begin.
+func BeginWrapped() *Tx {
+	return Begin()
+}
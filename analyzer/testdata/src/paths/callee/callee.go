@@ -0,0 +1,8 @@
+package callee // want package:"summary"
+
+func Callee() {
+}
+
+func Indirect() {
+	Callee()
+}
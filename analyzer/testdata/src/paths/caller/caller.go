@@ -0,0 +1,28 @@
+package caller // want package:"summary"
+
+import "paths/callee"
+
+type Param string
+type Result error
+
+func Direct(s Param) Result { // want `Direct calls callee function: Direct -> paths/callee.Callee \(caller.go:9\)`
+	callee.Callee()
+	return nil
+}
+
+func Indirect(s Param) Result { // want `Indirect calls callee function: Indirect -> paths/callee.Indirect \(caller.go:14\) -> paths/callee.Callee \(callee.go:7\)`
+	callee.Indirect()
+	return nil
+}
+
+func Closure(s Param) Result { // want `Closure calls callee function: Closure -> Closure\$1 \(caller.go:22\) -> paths/callee.Callee \(caller.go:20\)`
+	f := func() {
+		callee.Callee()
+	}
+	f()
+	return nil
+}
+
+func Fail(s Param) Result { // want "Fail does not call callee function"
+	return nil
+}
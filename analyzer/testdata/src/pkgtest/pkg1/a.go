@@ -1,4 +1,4 @@
-package pkg1 // want package:"graph" package:"types"
+package pkg1 // want package:"summary"
 
 func A() error {
 	err := a()
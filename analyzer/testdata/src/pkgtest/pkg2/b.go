@@ -1,4 +1,4 @@
-package pkg2 // want package:"graph" package:"types"
+package pkg2 // want package:"summary"
 
 import (
 	"pkgtest/pkg1"
@@ -1,4 +1,4 @@
-package pkg3 // want package:"graph" package:"types"
+package pkg3 // want package:"summary"
 
 import (
 	"pkgtest/pkg2"
@@ -0,0 +1,13 @@
+package api
+
+import (
+	"program/iface"
+	"program/impl"
+)
+
+func HandleAudit() { // OK: Audit.Log calls Log, found by the summary of iface.Use
+	iface.Use(impl.Audit{})
+}
+
+func HandleNone() { // reported: does not call Log
+}
@@ -0,0 +1,10 @@
+package iface
+
+type Logger interface {
+	Log()
+}
+
+// Use calls the method of an interface implemented in another package.
+func Use(l Logger) {
+	l.Log()
+}
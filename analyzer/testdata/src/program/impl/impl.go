@@ -0,0 +1,9 @@
+package impl
+
+import "program/audit"
+
+type Audit struct{}
+
+func (Audit) Log() {
+	audit.Log()
+}
@@ -0,0 +1,58 @@
+package api // want package:"summary"
+
+import (
+	"reflect"
+
+	"reflection/audit"
+)
+
+func HandleCall() { // OK: calls Log through reflect.Value.Call
+	call(audit.Log)
+}
+
+func HandleCall_fail() { // want "HandleCall_fail does not call callee function"
+	call(func() {})
+}
+
+func call(f any) {
+	reflect.ValueOf(f).Call(nil)
+}
+
+type logger struct{}
+
+func (logger) Log() {
+	audit.Log()
+}
+
+type noop struct{}
+
+func (noop) Log() {
+}
+
+func HandleMethod() { // OK: calls logger.Log through reflect.Value.MethodByName
+	method(logger{})
+}
+
+func HandleMethod_fail() { // want "HandleMethod_fail does not call callee function"
+	method(noop{})
+}
+
+func method(v any) {
+	reflect.ValueOf(v).MethodByName("Log").Call(nil)
+}
+
+func HandleAssert() { // OK: calls Log asserted from an interface
+	assert(audit.Log)
+}
+
+func HandleAssert_fail() { // want "HandleAssert_fail does not call callee function"
+	assert(func() {})
+}
+
+func assert(f any) {
+	f.(func())()
+}
+
+func HandleValue(v reflect.Value) { // want "HandleValue does not call callee function"
+	v.Call(nil) // ignored, the function is unknown
+}
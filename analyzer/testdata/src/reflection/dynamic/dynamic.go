@@ -0,0 +1,31 @@
+package dynamic // want package:"summary"
+
+import (
+	"reflect"
+
+	"reflection/audit"
+)
+
+// Calls through reflection are treated conservatively.
+
+func HandleValue(v reflect.Value) { // want "HandleValue may reach reflection/audit.Log via reflection"
+	v.Call(nil)
+}
+
+type logger struct{}
+
+func (logger) Log() {
+	audit.Log()
+}
+
+func HandleName(name string) { // want "HandleName may reach reflection/audit.Log via reflection"
+	reflect.ValueOf(logger{}).MethodByName(name).Call(nil)
+}
+
+func HandleCall() { // OK: calls Log through reflect.Value.Call
+	reflect.ValueOf(audit.Log).Call(nil)
+}
+
+func HandleCall_fail() { // want "HandleCall_fail does not call callee function"
+	reflect.ValueOf(func() {}).Call(nil)
+}
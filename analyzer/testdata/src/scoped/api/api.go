@@ -0,0 +1,38 @@
+package api
+
+import "scoped/audit"
+
+// Directives are scoped by analyzer name, the analyzers audit and perm
+// only see their own directives and suppressions.
+
+//audit:rule log
+func HandleLog() { // OK
+	audit.Log()
+}
+
+//audit:rule log
+func HandleLog_fail() { // reported by audit
+}
+
+//perm:require scoped/audit.Check
+func HandleCheck() { // OK
+	audit.Check()
+}
+
+//perm:require scoped/audit.Check
+func HandleCheck_fail() { // reported by perm
+}
+
+//perm:require scoped/audit.Check
+//perm:ignore check generated code
+func HandleIgnored() { // OK: suppressed by perm
+}
+
+//audit:rule typo
+func HandleTypo() { // reported by audit: no rule for typo
+}
+
+//sadboy:rule log
+//sadboy:ignore log generated code
+func HandleOther() { // OK: directives of another analyzer
+}
@@ -0,0 +1,7 @@
+package audit
+
+func Log() {
+}
+
+func Check() {
+}
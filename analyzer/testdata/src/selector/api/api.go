@@ -0,0 +1,34 @@
+package api // want package:"summary"
+
+import "selector/audit"
+
+func Handle() { // want "Handle does not call callee function"
+	audit.Logger{}.Log()
+}
+
+func HandleOK() { // OK: not selected
+}
+
+type Server struct{}
+
+func (s *Server) ServeHTTP() { // OK: calls selector/audit.Log
+	audit.Log()
+}
+
+func (s Server) Handle() { // OK: not selected (method)
+}
+
+type Service struct{}
+
+func (s Service) Run() { // want "Run does not call callee function"
+}
+
+type Job struct{}
+
+func (j *Job) Run() { // OK: not selected (other type)
+}
+
+type PtrService struct{}
+
+func (s *PtrService) Run() { // want "Run does not call callee function"
+}
@@ -0,0 +1,9 @@
+package audit // want package:"summary"
+
+func Log() {
+}
+
+type Logger struct{}
+
+func (l Logger) Log() {
+}
@@ -0,0 +1,3 @@
+module selector
+
+go 1.22.0
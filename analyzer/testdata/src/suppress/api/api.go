@@ -0,0 +1,45 @@
+package api // want package:"summary"
+
+import "suppress/audit"
+
+//sadboy:ignore audit legacy handler, removed in v2
+func HandleIgnored() { // OK: suppressed
+}
+
+// HandleNolint is documented.
+//
+//nolint:sadboy // generated code
+func HandleNolint() { // OK: suppressed
+}
+
+func HandleLine() { //nolint:sadboy // generated code
+}
+
+func HandleNotSuppressed() { // want "HandleNotSuppressed does not call callee function"
+	//nolint:sadboy // wrong line // want "unused nolint:sadboy directive"
+}
+
+//sadboy:ignore trace wrong rule // want "unused sadboy:ignore directive for rule trace"
+func HandleOtherRule() { // want "HandleOtherRule does not call callee function"
+}
+
+//sadboy:ignore audit calls Log now // want "unused sadboy:ignore directive for rule audit"
+func HandleOK() {
+	audit.Log()
+}
+
+//nolint:other // not for sadboy
+func HandleOtherLinter() { // want "HandleOtherLinter does not call callee function"
+}
+
+//sadboy:ignore audit // want "sadboy:ignore directive requires a rule and a reason"
+func HandleNoReason() { // want "HandleNoReason does not call callee function"
+}
+
+/* want "nolint:sadboy directive requires a reason" */ //nolint:sadboy
+func HandleNolintNoReason() {                          // want "HandleNolintNoReason does not call callee function"
+}
+
+//sadboy:ignore auditt typo // want "sadboy:ignore directive: unknown rule auditt"
+func HandleUnknownRule() { // want "HandleUnknownRule does not call callee function"
+}
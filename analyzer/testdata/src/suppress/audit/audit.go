@@ -0,0 +1,4 @@
+package audit // want package:"summary"
+
+func Log() {
+}
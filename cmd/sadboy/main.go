@@ -1,10 +1,140 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/sollniss/sadboy/analyzer"
 	"golang.org/x/tools/go/analysis/singlechecker"
+	"golang.org/x/tools/go/packages"
 )
 
 func main() {
+	// Registered for the usage of singlechecker, which also accepts -whole=false.
+	flag.Bool("whole", false, "analyze all packages as one program")
+	// Without valid flags, singlechecker reports the error.
+	if fs, ok := parseFlags(os.Args[1:]); ok {
+		if fs.Lookup("whole").Value.String() == "true" {
+			os.Exit(whole(os.Args[1:]))
+		}
+		if err := validate(fs); err != nil {
+			fmt.Fprintf(os.Stderr, "sadboy: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	singlechecker.Main(analyzer.Analyzer)
 }
+
+// driverFlags are the flags singlechecker adds to the flags of the analyzer,
+// true for boolean flags.
+var driverFlags = map[string]bool{
+	"V": true, "flags": true, "json": true, "fix": true, "test": true,
+	"source": true, "v": true, "all": true,
+	"c": false, "tags": false, "debug": false, "cpuprofile": false, "memprofile": false, "trace": false,
+}
+
+// parseFlags parses the flags of the analyzer, -whole and the flags of singlechecker in args,
+// before singlechecker or whole parse the same flags again, which does not change them.
+// ok is false if the flags are invalid.
+func parseFlags(args []string) (fs *flag.FlagSet, ok bool) {
+	fs = flag.NewFlagSet("sadboy", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	analyzer.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	fs.Bool("whole", false, "")
+	for name, isBool := range driverFlags {
+		if isBool {
+			fs.Bool(name, false, "")
+		} else {
+			fs.String(name, "", "")
+		}
+	}
+	return fs, fs.Parse(args) == nil
+}
+
+// validate checks the configuration of the flags parsed by [parseFlags] once,
+// instead of failing every package.
+// Updating the baseline is limited to the requested packages,
+// unless -baseline.packages is set.
+func validate(fs *flag.FlagSet) error {
+	if fs.NArg() == 0 || fs.Lookup("flags").Value.String() == "true" {
+		// singlechecker prints the usage or the flags.
+		return nil
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	if fs.Lookup("baseline.update").Value.String() == "true" {
+		if fs.NArg() == 1 && strings.HasSuffix(fs.Arg(0), ".cfg") {
+			// Run by go vet, see unitchecker.
+			return errors.New("-baseline.update requires the standalone driver, go vet analyzes packages in parallel processes")
+		}
+		if !set["baseline.packages"] {
+			pkgs, err := packages.Load(&packages.Config{
+				Mode:  packages.NeedName,
+				Tests: true,
+			}, fs.Args()...)
+			if err != nil {
+				return err
+			}
+			paths := make([]string, 0, len(pkgs))
+			for _, pkg := range pkgs {
+				paths = append(paths, pkg.PkgPath)
+			}
+			if err := analyzer.Analyzer.Flags.Set("baseline.packages", strings.Join(paths, ",")); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := analyzer.FlagConfig()
+	return err
+}
+
+// whole analyzes all packages as one program, see [analyzer.CheckProgram].
+// Returns the exit code, 3 if there are diagnostics like singlechecker.
+func whole(args []string) int {
+	fs := flag.NewFlagSet("sadboy", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: sadboy -whole [flags] packages")
+		fs.PrintDefaults()
+	}
+	fs.Bool("whole", false, "analyze all packages as one program")
+	tests := fs.Bool("test", true, "include test packages")
+	analyzer.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	fs.Parse(args)
+
+	cfg, err := analyzer.FlagConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:  analyzer.LoadMode,
+		Tests: *tests,
+	}, fs.Args()...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	diags, err := analyzer.CheckProgram(cfg, pkgs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, d := range diags {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", d.Position, d.Message)
+	}
+	if len(diags) > 0 {
+		return 3
+	}
+	return 0
+}
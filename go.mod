@@ -2,7 +2,10 @@ module github.com/sollniss/sadboy
 
 go 1.23.3
 
-require golang.org/x/tools v0.27.0
+require (
+	github.com/golangci/plugin-module-register v0.1.1
+	golang.org/x/tools v0.27.0
+)
 
 require (
 	golang.org/x/mod v0.22.0 // indirect
@@ -0,0 +1,69 @@
+// Package plugin registers sadboy as a golangci-lint module plugin.
+//
+// See https://golangci-lint.run/plugins/module-plugins/.
+package plugin
+
+import (
+	"github.com/golangci/plugin-module-register/register"
+	"github.com/sollniss/sadboy/analyzer"
+	"golang.org/x/tools/go/analysis"
+)
+
+func init() {
+	register.Plugin("sadboy", New)
+}
+
+// Settings are the linter settings in .golangci.yml.
+type Settings struct {
+	// Rules to check, same as in the config file.
+	Rules []analyzer.RuleConfig `json:"rules"`
+
+	// Report the call path from every caller that calls the callee.
+	ReportPaths bool `json:"report_paths"`
+
+	// Call graph algorithm, see [analyzer.CallGraph].
+	CallGraph string `json:"callgraph"`
+
+	// Treatment of calls through reflection, see [analyzer.Reflection].
+	Reflection string `json:"reflection"`
+}
+
+type plugin struct {
+	cfg analyzer.Config
+}
+
+// New decodes and validates the settings and creates the plugin.
+func New(settings any) (register.LinterPlugin, error) {
+	s, err := register.DecodeSettings[Settings](settings)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := analyzer.FileConfig{Rules: s.Rules}.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := analyzer.Config{
+		Rules:       rules,
+		ReportPaths: s.ReportPaths,
+		CallGraph:   analyzer.CallGraph(s.CallGraph),
+		Reflection:  analyzer.Reflection(s.Reflection),
+	}
+	// Report invalid settings once, not for every analyzed package.
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &plugin{cfg: cfg}, nil
+}
+
+func (p *plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	return []*analysis.Analyzer{
+		analyzer.New(p.cfg),
+	}, nil
+}
+
+func (p *plugin) GetLoadMode() string {
+	return register.LoadModeTypesInfo
+}
@@ -0,0 +1,71 @@
+package plugin_test
+
+import (
+	"testing"
+
+	"github.com/golangci/plugin-module-register/register"
+	_ "github.com/sollniss/sadboy/plugin"
+)
+
+func TestPlugin(t *testing.T) {
+	newPlugin, err := register.GetPlugin("sadboy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newPlugin(map[string]any{
+		"rules": []any{
+			map[string]any{
+				"name": "audit",
+				"caller": map[string]any{
+					"names": []any{"Handle"},
+				},
+				"callee": map[string]any{
+					"name": "Log",
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	analyzers, err := p.BuildAnalyzers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analyzers) != 1 || analyzers[0].Name != "sadboy" {
+		t.Fatalf("unexpected analyzers: %v", analyzers)
+	}
+	if mode := p.GetLoadMode(); mode != register.LoadModeTypesInfo {
+		t.Fatalf("unexpected load mode: %s", mode)
+	}
+}
+
+func TestPluginInvalidSettings(t *testing.T) {
+	newPlugin, err := register.GetPlugin("sadboy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, settings := range map[string]any{
+		"unknown field":    map[string]any{"unknown": true},
+		"no rule name":     map[string]any{"rules": []any{map[string]any{}}},
+		"unknown mode":     map[string]any{"rules": []any{map[string]any{"name": "a", "mode": "maybe"}}},
+		"invalid selector": map[string]any{"rules": []any{map[string]any{"name": "a", "callee": map[string]any{"name": "a.b.c.d"}}}},
+		"invalid regex":    map[string]any{"rules": []any{map[string]any{"name": "a", "callee": map[string]any{"name": "Log"}, "caller": map[string]any{"names_regex": "("}}}},
+		"unknown callgraph": map[string]any{
+			"callgraph": "nope",
+			"rules":     []any{map[string]any{"name": "a", "callee": map[string]any{"name": "Log"}}},
+		},
+		"unknown reflection": map[string]any{
+			"reflection": "nope",
+			"rules":      []any{map[string]any{"name": "a", "callee": map[string]any{"name": "Log"}}},
+		},
+		"order without guarded": map[string]any{"rules": []any{map[string]any{"name": "a", "mode": "order", "callee": map[string]any{"name": "Check"}}}},
+	} {
+		if _, err := newPlugin(settings); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}